@@ -26,15 +26,15 @@ type AlarmControlPanel struct {
 
 	// If true the code is required to arm the alarm. If false the code is not validated
 	// Default: true
-	CodeArmRequired bool `json:"code_arm_required,omitempty"`
+	CodeArmRequired *bool `json:"code_arm_required,omitempty"`
 
 	// If true the code is required to disarm the alarm. If false the code is not validated
 	// Default: true
-	CodeDisarmRequired bool `json:"code_disarm_required,omitempty"`
+	CodeDisarmRequired *bool `json:"code_disarm_required,omitempty"`
 
 	// If true the code is required to trigger the alarm. If false the code is not validated
 	// Default: true
-	CodeTriggerRequired bool `json:"code_trigger_required,omitempty"`
+	CodeTriggerRequired *bool `json:"code_trigger_required,omitempty"`
 
 	// The [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) used for the command payload. Available variables: `action` and `code`
 	// Default: action
@@ -151,7 +151,9 @@ type AlarmControlPanel struct {
 
 // AnnounceTopic returns the topic to announce the discoverable AlarmControlPanel
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the AlarmControlPanel
 func (d *AlarmControlPanel) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/alarm_control_panel/%s/config"
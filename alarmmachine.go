@@ -0,0 +1,333 @@
+package discovery
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Special values of the Code of an AlarmControlPanel, that make Home Assistant ask for a code
+// and leave checking it to the device.
+const (
+	RemoteCode     = "REMOTE_CODE"
+	RemoteCodeText = "REMOTE_CODE_TEXT"
+)
+
+// HashCode hashes an alarm code with a random salt, for AddCodeHash. The hash has the format
+// sha256$<salt>$<hash>.
+func HashCode(code string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("could not create salt: %v", err)
+	}
+	return hashCode(salt, code), nil
+}
+
+func hashCode(salt []byte, code string) string {
+	h := sha256.Sum256(append(append([]byte{}, salt...), code...))
+	return "sha256$" + hex.EncodeToString(salt) + "$" + hex.EncodeToString(h[:])
+}
+
+// checkCode returns whether code matches hash.
+func checkCode(hash, code string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 3 || parts[0] != "sha256" {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashCode(salt, code)), []byte(hash)) == 1
+}
+
+// AlarmMachine is an alarm that runs the states of an AlarmControlPanel, like Home Assistant's
+// manual alarm.
+//
+// Arming moves to arming for the ArmingTime of the armed state, then to the armed state.
+// Triggering an armed alarm moves to pending for the DelayTime of the armed state, then to
+// triggered for the TriggerTime, after which it returns to the armed state. Disarming with a
+// valid code stops it all.
+//
+// Codes are only known by their hashes. Home Assistant only sends the code to the device if
+// the CommandTemplate includes it, as JSON like
+//
+//	{"action": "{{ action }}", "code": "{{ code }}"}
+//
+// so a code is only checked if the template includes one, and the panel's CodeArmRequired,
+// CodeDisarmRequired or CodeTriggerRequired, which default to true, require it for the
+// command. Such commands are refused until codes are added. With the default template Home Assistant checks a local Code of the
+// panel itself, and only sends the action.
+type AlarmMachine struct {
+	Panel     *AlarmControlPanel
+	Publisher Publisher
+	Clock     Clock
+
+	// ArmingTime is the time to leave before each armed state is entered.
+	ArmingTime map[AlarmState]time.Duration
+	// DelayTime is the time to disarm an alarm triggered in each armed state, before it goes
+	// off.
+	DelayTime map[AlarmState]time.Duration
+	// TriggerTime is how long the alarm stays triggered. If it is zero, it stays triggered
+	// until it is disarmed.
+	TriggerTime time.Duration
+	// DisarmAfterTrigger disarms the alarm after the TriggerTime, rather than returning to
+	// the armed state.
+	DisarmAfterTrigger bool
+
+	// OnState is called when the state changes. It is called with the alarm locked, and must
+	// not call its methods.
+	OnState func(AlarmState)
+	// OnError is called with the errors from handling commands and publishing.
+	OnError func(error)
+
+	mu    sync.Mutex
+	codes []string
+	state AlarmState
+	// armed is the armed state being entered or left.
+	armed AlarmState
+	timer Timer
+	gen   int
+}
+
+// NewAlarmMachine creates an AlarmMachine for a that publishes with p. The alarm starts
+// disarmed. If the panel has a Code that Home Assistant checks itself, it is accepted too.
+func NewAlarmMachine(a *AlarmControlPanel, p Publisher) (*AlarmMachine, error) {
+	am := &AlarmMachine{
+		Panel:     a,
+		Publisher: p,
+		Clock:     RealClock,
+		state:     AlarmStateDisarmed,
+	}
+	if a.Code != "" && a.Code != RemoteCode && a.Code != RemoteCodeText {
+		if err := am.AddCode(a.Code); err != nil {
+			return nil, err
+		}
+	}
+	return am, nil
+}
+
+// AddCode adds a code that arms and disarms the alarm. Only its hash is kept.
+func (am *AlarmMachine) AddCode(code string) error {
+	h, err := HashCode(code)
+	if err != nil {
+		return err
+	}
+	return am.AddCodeHash(h)
+}
+
+// AddCodeHash adds a code by its hash, as created by HashCode.
+func (am *AlarmMachine) AddCodeHash(hash string) error {
+	if !strings.HasPrefix(hash, "sha256$") || strings.Count(hash, "$") != 2 {
+		return fmt.Errorf("invalid code hash")
+	}
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.codes = append(am.codes, hash)
+	return nil
+}
+
+// checkCode returns an error if a code is required and code doesn't match any of the codes.
+func (am *AlarmMachine) checkCode(required *bool, code string) error {
+	if !supported(required) || !strings.Contains(am.Panel.CommandTemplate, "code") {
+		return nil
+	}
+	am.mu.Lock()
+	codes := am.codes
+	am.mu.Unlock()
+
+	if len(codes) == 0 {
+		return fmt.Errorf("a code is required, but no codes were added")
+	}
+	for _, h := range codes {
+		if checkCode(h, code) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid code")
+}
+
+// State returns the current state.
+func (am *AlarmMachine) State() AlarmState {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return am.state
+}
+
+// Subscribe subscribes the alarm to the panel's command topic.
+func (am *AlarmMachine) Subscribe(s Subscriber) error {
+	if am.Panel.CommandTopic == "" {
+		return fmt.Errorf("alarm control panel has no command topic")
+	}
+	err := s.Subscribe(am.Panel.CommandTopic, byte(am.Panel.Qos), func(m Message) {
+		if err := am.HandleMessage(m); err != nil {
+			am.error(err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("could not subscribe to %s: %v", am.Panel.CommandTopic, err)
+	}
+	return nil
+}
+
+func (am *AlarmMachine) error(err error) {
+	if am.OnError != nil {
+		am.OnError(err)
+	}
+}
+
+// HandleMessage handles a message on the CommandTopic, which is either the action payload, or
+// JSON with the action and code.
+func (am *AlarmMachine) HandleMessage(m Message) error {
+	var c struct {
+		Action string `json:"action"`
+		Code   string `json:"code"`
+	}
+	p := strings.TrimSpace(string(m.Payload))
+	if strings.HasPrefix(p, "{") {
+		if err := json.Unmarshal([]byte(p), &c); err != nil {
+			return fmt.Errorf("could not decode alarm command: %v", err)
+		}
+	} else {
+		c.Action = p
+	}
+	return am.Command(c.Action, c.Code)
+}
+
+// armedStates returns the armed state for each arm payload of the panel.
+func (am *AlarmMachine) armedStates() map[string]AlarmState {
+	return map[string]AlarmState{
+		orDefault(am.Panel.PayloadArmHome, "ARM_HOME"):                  AlarmStateArmedHome,
+		orDefault(am.Panel.PayloadArmAway, "ARM_AWAY"):                  AlarmStateArmedAway,
+		orDefault(am.Panel.PayloadArmNight, "ARM_NIGHT"):                AlarmStateArmedNight,
+		orDefault(am.Panel.PayloadArmVacation, "ARM_VACATION"):          AlarmStateArmedVacation,
+		orDefault(am.Panel.PayloadArmCustomBypass, "ARM_CUSTOM_BYPASS"): AlarmStateArmedCustomBypass,
+	}
+}
+
+// Command runs the action sent by Home Assistant, with the code entered.
+func (am *AlarmMachine) Command(action, code string) error {
+	switch action {
+	case orDefault(am.Panel.PayloadDisarm, "DISARM"):
+		return am.Disarm(code)
+	case orDefault(am.Panel.PayloadTrigger, "TRIGGER"):
+		if err := am.checkCode(am.Panel.CodeTriggerRequired, code); err != nil {
+			return err
+		}
+		return am.Trigger()
+	}
+	if armed, ok := am.armedStates()[action]; ok {
+		return am.Arm(armed, code)
+	}
+	return fmt.Errorf("unknown alarm action %q", action)
+}
+
+// Arm arms the alarm in the armed state, after its ArmingTime.
+func (am *AlarmMachine) Arm(armed AlarmState, code string) error {
+	if !strings.HasPrefix(string(armed), "armed_") {
+		return fmt.Errorf("%q is not an armed state", armed)
+	}
+	if err := am.checkCode(am.Panel.CodeArmRequired, code); err != nil {
+		return err
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	switch am.state {
+	case AlarmStatePending, AlarmStateTriggered:
+		return fmt.Errorf("can not arm a %s alarm", am.state)
+	}
+	am.armed = armed
+	return am.enter(AlarmStateArming, am.ArmingTime[armed], armed)
+}
+
+// Disarm disarms the alarm.
+func (am *AlarmMachine) Disarm(code string) error {
+	if err := am.checkCode(am.Panel.CodeDisarmRequired, code); err != nil {
+		return err
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return am.enter(AlarmStateDisarmed, 0, "")
+}
+
+// Trigger triggers the alarm, for example when a sensor trips. The alarm goes off after the
+// DelayTime of the armed state. Triggering an alarm that is not armed does nothing.
+func (am *AlarmMachine) Trigger() error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if !strings.HasPrefix(string(am.state), "armed_") {
+		return nil
+	}
+	return am.enter(AlarmStatePending, am.DelayTime[am.state], AlarmStateTriggered)
+}
+
+// enter moves to state, and then after d to next, if there is a next state. It must be
+// called with the lock held.
+func (am *AlarmMachine) enter(state AlarmState, d time.Duration, next AlarmState) error {
+	if am.timer != nil {
+		am.timer.Stop()
+		am.timer = nil
+	}
+	am.gen++
+
+	if next != "" && d <= 0 {
+		return am.enterNext(next)
+	}
+
+	err := am.set(state)
+	if next != "" {
+		gen := am.gen
+		am.timer = am.Clock.AfterFunc(d, func() {
+			am.mu.Lock()
+			defer am.mu.Unlock()
+			if am.gen != gen {
+				return
+			}
+			if err := am.enterNext(next); err != nil {
+				am.error(err)
+			}
+		})
+	}
+	return err
+}
+
+// enterNext enters a state that follows a delay.
+func (am *AlarmMachine) enterNext(next AlarmState) error {
+	if next != AlarmStateTriggered || am.TriggerTime <= 0 {
+		return am.enter(next, 0, "")
+	}
+	after := am.armed
+	if am.DisarmAfterTrigger {
+		after = AlarmStateDisarmed
+	}
+	return am.enter(next, am.TriggerTime, after)
+}
+
+// set sets and publishes the state. It must be called with the lock held.
+func (am *AlarmMachine) set(state AlarmState) error {
+	if state == am.state {
+		return nil
+	}
+	am.state = state
+	if am.OnState != nil {
+		am.OnState(state)
+	}
+
+	if am.Panel.StateTopic == "" {
+		return nil
+	}
+	err := am.Publisher.Publish(am.Panel.StateTopic, byte(am.Panel.Qos), am.Panel.Retain, []byte(state))
+	if err != nil {
+		return fmt.Errorf("could not publish alarm state: %v", err)
+	}
+	return nil
+}
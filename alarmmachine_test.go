@@ -0,0 +1,158 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashCode(t *testing.T) {
+	h1, err := HashCode("1234")
+	if err != nil {
+		t.Fatalf("could not hash code: %v", err)
+	}
+	h2, _ := HashCode("1234")
+	if h1 == h2 {
+		t.Errorf("hashes are not salted")
+	}
+	if strings.Contains(h1, "1234") || !checkCode(h1, "1234") || checkCode(h1, "4321") {
+		t.Errorf("hash %s does not check", h1)
+	}
+}
+
+func TestAlarmMachine(t *testing.T) {
+	clock := newTestClock()
+	s := testSubscriber{}
+	p := &testPublisher{}
+	a := &AlarmControlPanel{
+		CommandTopic:    "alarm/set",
+		StateTopic:      "alarm/state",
+		Code:            RemoteCode,
+		CommandTemplate: `{"action": "{{ action }}", "code": "{{ code }}"}`,
+	}
+	am, err := NewAlarmMachine(a, p)
+	if err != nil {
+		t.Fatalf("could not create alarm: %v", err)
+	}
+	am.Clock = clock
+	am.ArmingTime = map[AlarmState]time.Duration{AlarmStateArmedAway: 30 * time.Second}
+	am.DelayTime = map[AlarmState]time.Duration{AlarmStateArmedAway: 20 * time.Second}
+	am.TriggerTime = time.Minute
+	var errs []error
+	am.OnError = func(err error) { errs = append(errs, err) }
+	if err := am.AddCode("1234"); err != nil {
+		t.Fatalf("could not add code: %v", err)
+	}
+	if err := am.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	s["alarm/set"](Message{Payload: []byte(`{"action": "ARM_AWAY", "code": "0000"}`)})
+	if len(errs) != 1 || am.State() != AlarmStateDisarmed {
+		t.Fatalf("armed with an invalid code")
+	}
+
+	s["alarm/set"](Message{Payload: []byte(`{"action": "ARM_AWAY", "code": "1234"}`)})
+	clock.Advance(29 * time.Second)
+	if am.State() != AlarmStateArming {
+		t.Errorf("state is %s, want arming", am.State())
+	}
+	clock.Advance(time.Second)
+	if am.State() != AlarmStateArmedAway {
+		t.Errorf("state is %s, want armed_away", am.State())
+	}
+
+	am.Trigger()
+	clock.Advance(20 * time.Second)
+	if am.State() != AlarmStateTriggered {
+		t.Errorf("state is %s, want triggered", am.State())
+	}
+	clock.Advance(time.Minute)
+	if am.State() != AlarmStateArmedAway {
+		t.Errorf("state is %s, want armed_away after the trigger time", am.State())
+	}
+
+	am.Trigger()
+	clock.Advance(10 * time.Second)
+	s["alarm/set"](Message{Payload: []byte(`{"action": "DISARM", "code": "1234"}`)})
+	clock.Advance(time.Hour)
+	if am.State() != AlarmStateDisarmed {
+		t.Errorf("state is %s, want disarmed", am.State())
+	}
+
+	no := false
+	a.CodeArmRequired = &no
+	s["alarm/set"](Message{Payload: []byte("ARM_HOME")})
+	if am.State() != AlarmStateArmedHome {
+		t.Errorf("state is %s, want armed_home", am.State())
+	}
+	s["alarm/set"](Message{Payload: []byte("DISARM")})
+	s["alarm/set"](Message{Payload: []byte("PANIC")})
+	if len(errs) != 3 {
+		t.Errorf("got errors %v, want 3", errs)
+	}
+
+	want := []string{"arming", "armed_away", "pending", "triggered", "armed_away", "pending", "disarmed", "armed_home"}
+	if len(*p) != len(want) {
+		t.Fatalf("published %+v, want %v", *p, want)
+	}
+	for i, w := range want {
+		if (*p)[i].payload != w {
+			t.Errorf("published %s, want %s", (*p)[i].payload, w)
+		}
+	}
+}
+
+func TestAlarmMachineDefaultTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		panel   AlarmControlPanel
+		payload string
+	}{
+		{
+			// Home Assistant checks the code itself, and only sends the action.
+			name:    "local code",
+			panel:   AlarmControlPanel{Code: "1234"},
+			payload: "ARM_AWAY",
+		},
+	} {
+		p := &testPublisher{}
+		tc.panel.StateTopic = "alarm/state"
+		am, err := NewAlarmMachine(&tc.panel, p)
+		if err != nil {
+			t.Fatalf("%s: could not create alarm: %v", tc.name, err)
+		}
+
+		if err := am.HandleMessage(Message{Payload: []byte(tc.payload)}); err != nil {
+			t.Errorf("%s: could not arm: %v", tc.name, err)
+		}
+		if am.State() != AlarmStateArmedAway {
+			t.Errorf("%s: state is %s, want armed_away", tc.name, am.State())
+		}
+		if err := am.HandleMessage(Message{Payload: []byte("DISARM")}); err != nil {
+			t.Errorf("%s: could not disarm: %v", tc.name, err)
+		}
+		if am.State() != AlarmStateDisarmed {
+			t.Errorf("%s: state is %s, want disarmed", tc.name, am.State())
+		}
+	}
+}
+
+func TestAlarmMachineNoCodes(t *testing.T) {
+	s := testSubscriber{}
+	a := &AlarmControlPanel{CommandTemplate: `{"action": "{{ action }}", "code": "{{ code }}"}`}
+	am, err := NewAlarmMachine(a, &testPublisher{})
+	if err != nil {
+		t.Fatalf("could not create alarm: %v", err)
+	}
+	if err := am.Subscribe(s); err == nil {
+		t.Errorf("expected an error subscribing without a command topic")
+	}
+
+	if err := am.HandleMessage(Message{Payload: []byte(`{"action": "ARM_AWAY", "code": "0000"}`)}); err == nil {
+		t.Errorf("armed without any codes added")
+	}
+	if am.State() != AlarmStateDisarmed {
+		t.Errorf("state is %s, want disarmed", am.State())
+	}
+}
@@ -115,7 +115,9 @@ type BinarySensor struct {
 
 // AnnounceTopic returns the topic to announce the discoverable BinarySensor
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the BinarySensor
 func (d *BinarySensor) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/binary_sensor/%s/config"
@@ -0,0 +1,80 @@
+package discovery
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultPayloadPress is the payload Home Assistant sends when a Button is pressed and
+// PayloadPress is not set.
+const DefaultPayloadPress = "PRESS"
+
+// ButtonHandler calls OnPress when a Button is pressed in Home Assistant.
+//
+// A press is only handled once. Retained messages and messages the broker redelivers are
+// ignored, so a press is not repeated when reconnecting or when the Button is configured to
+// retain its commands. Presses received within MinInterval of the previous press are ignored
+// too.
+type ButtonHandler struct {
+	Button  *Button
+	OnPress func()
+	// MinInterval is the minimum time between two presses.
+	MinInterval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+	now  func() time.Time
+}
+
+// NewButtonHandler creates a ButtonHandler that calls onPress when b is pressed.
+func NewButtonHandler(b *Button, onPress func()) *ButtonHandler {
+	return &ButtonHandler{
+		Button:  b,
+		OnPress: onPress,
+		now:     time.Now,
+	}
+}
+
+// Subscribe subscribes the handler to the Button's command topic.
+func (h *ButtonHandler) Subscribe(s Subscriber) error {
+	if h.Button.CommandTopic == "" {
+		return fmt.Errorf("button has no command topic")
+	}
+	return s.Subscribe(h.Button.CommandTopic, byte(h.Button.Qos), func(m Message) { h.HandleMessage(m) })
+}
+
+// HandleMessage calls OnPress if the message is a new press of the Button, and reports whether
+// it did. When the Button has a CommandTemplate the payload can not be matched, and any
+// message is treated as a press.
+func (h *ButtonHandler) HandleMessage(m Message) bool {
+	if m.Retained || m.Duplicate || !h.isPress(m.Payload) {
+		return false
+	}
+
+	h.mu.Lock()
+	now := h.now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.MinInterval {
+		h.mu.Unlock()
+		return false
+	}
+	h.last = now
+	h.mu.Unlock()
+
+	if h.OnPress != nil {
+		h.OnPress()
+	}
+	return true
+}
+
+func (h *ButtonHandler) isPress(payload []byte) bool {
+	if h.Button.CommandTemplate != "" {
+		return true
+	}
+
+	press := h.Button.PayloadPress
+	if press == "" {
+		press = DefaultPayloadPress
+	}
+	return string(payload) == press
+}
@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestButtonHandler(t *testing.T) {
+	presses := 0
+	b := &Button{CommandTopic: "device/reboot"}
+	h := NewButtonHandler(b, func() { presses++ })
+	h.MinInterval = time.Second
+
+	now := time.Unix(0, 0)
+	h.now = func() time.Time { return now }
+
+	s := testSubscriber{}
+	if err := h.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	publish := s[b.CommandTopic]
+	if publish == nil {
+		t.Fatalf("handler did not subscribe to the command topic")
+	}
+
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("PRESS"), Retained: true})
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("press")})
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("PRESS")})
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("PRESS"), Duplicate: true})
+	if presses != 1 {
+		t.Fatalf("got %d presses, want 1", presses)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("PRESS")})
+	if presses != 1 {
+		t.Fatalf("press within MinInterval was not ignored")
+	}
+
+	now = now.Add(time.Second)
+	b.PayloadPress = "reboot"
+	publish(Message{Topic: b.CommandTopic, Payload: []byte("reboot")})
+	if presses != 2 {
+		t.Fatalf("got %d presses, want 2", presses)
+	}
+}
@@ -75,7 +75,9 @@ type Camera struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Camera
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Camera
 func (d *Camera) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/camera/%s/config"
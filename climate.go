@@ -82,7 +82,7 @@ type Climate struct {
 
 	// A list of supported fan modes
 	// Default: [auto low medium high]
-	FanModes string `json:"fan_modes,omitempty"`
+	FanModes []string `json:"fan_modes,omitempty"`
 
 	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
 	// Default: <no value>
@@ -134,7 +134,7 @@ type Climate struct {
 
 	// A list of supported modes. Needs to be a subset of the default values
 	// Default: [auto off cool heat dry fan_only]
-	Modes string `json:"modes,omitempty"`
+	Modes []ClimateMode `json:"modes,omitempty"`
 
 	// The name of the HVAC. Can be set to `null` if only the device name is relevant
 	// Default: MQTT HVAC
@@ -194,7 +194,7 @@ type Climate struct {
 
 	// List of preset modes this climate is supporting. Common examples include `eco`, `away`, `boost`, `comfort`, `home`, `sleep` and `activity`
 	// Default: []
-	PresetModes string `json:"preset_modes,omitempty"`
+	PresetModes []string `json:"preset_modes,omitempty"`
 
 	// The maximum QoS level to be used when receiving and publishing messages
 	// Default: 0
@@ -222,7 +222,7 @@ type Climate struct {
 
 	// A list of supported swing modes
 	// Default: [on off]
-	SwingModes string `json:"swing_modes,omitempty"`
+	SwingModes []string `json:"swing_modes,omitempty"`
 
 	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `target_humidity_command_topic`
 	// Default: <no value>
@@ -307,7 +307,9 @@ type Climate struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Climate
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Climate
 func (d *Climate) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/climate/%s/config"
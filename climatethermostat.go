@@ -0,0 +1,522 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Defaults of the mode lists of a Climate.
+var (
+	DefaultClimateModes = []ClimateMode{
+		ClimateModeAuto,
+		ClimateModeOff,
+		ClimateModeCool,
+		ClimateModeHeat,
+		ClimateModeDry,
+		ClimateModeFanOnly,
+	}
+	DefaultClimateFanModes   = []string{"auto", "low", "medium", "high"}
+	DefaultClimateSwingModes = []string{"on", "off"}
+)
+
+// PresetNone is the preset mode Home Assistant sends to clear the preset.
+const PresetNone = "none"
+
+// Default target humidity range of a Climate.
+const (
+	DefaultMinHumidity = 30
+	DefaultMaxHumidity = 99
+)
+
+// ClimateState is the state of a thermostat.
+type ClimateState struct {
+	Mode   ClimateMode
+	Action HVACAction
+	Power  bool
+
+	// Temperature is the target temperature in the heat and cool modes. TemperatureLow and
+	// TemperatureHigh are the range kept in the heat_cool and auto modes.
+	Temperature     float64
+	TemperatureLow  float64
+	TemperatureHigh float64
+
+	// CurrentTemperature is only valid once HasCurrentTemperature is true.
+	CurrentTemperature    float64
+	HasCurrentTemperature bool
+
+	TargetHumidity  float64
+	CurrentHumidity float64
+
+	FanMode    string
+	SwingMode  string
+	PresetMode string
+}
+
+// ThermostatController decides what a thermostat should be doing. It is called whenever the
+// state changes, with the action it is currently doing in the state.
+type ThermostatController interface {
+	Action(s ClimateState) HVACAction
+}
+
+// HysteresisController is a ThermostatController that heats or cools once the temperature has
+// drifted past the target by a tolerance, and keeps going until it has passed the target by
+// the other tolerance, like Home Assistant's generic thermostat.
+type HysteresisController struct {
+	// ColdTolerance is how far below the target the temperature must fall for heating to
+	// start, and for cooling to stop.
+	ColdTolerance float64
+	// HotTolerance is how far above the target the temperature must rise for cooling to
+	// start, and for heating to stop.
+	HotTolerance float64
+}
+
+// Action implements ThermostatController.
+func (h HysteresisController) Action(s ClimateState) HVACAction {
+	if !s.Power {
+		return HVACActionOff
+	}
+
+	switch s.Mode {
+	case ClimateModeOff:
+		return HVACActionOff
+	case ClimateModeFanOnly:
+		return HVACActionFan
+	case ClimateModeDry:
+		return HVACActionDrying
+	}
+	if !s.HasCurrentTemperature {
+		return HVACActionIdle
+	}
+
+	t := s.CurrentTemperature
+	heating := s.Action == HVACActionHeating
+	cooling := s.Action == HVACActionCooling
+	switch s.Mode {
+	case ClimateModeHeat:
+		if h.heat(t, s.Temperature, heating) {
+			return HVACActionHeating
+		}
+	case ClimateModeCool:
+		if h.cool(t, s.Temperature, cooling) {
+			return HVACActionCooling
+		}
+	case ClimateModeHeatCool, ClimateModeAuto:
+		if h.heat(t, s.TemperatureLow, heating) {
+			return HVACActionHeating
+		}
+		if h.cool(t, s.TemperatureHigh, cooling) {
+			return HVACActionCooling
+		}
+	}
+	return HVACActionIdle
+}
+
+func (h HysteresisController) heat(t, target float64, heating bool) bool {
+	if heating {
+		return t < target+h.HotTolerance
+	}
+	return t <= target-h.ColdTolerance
+}
+
+func (h HysteresisController) cool(t, target float64, cooling bool) bool {
+	if cooling {
+		return t > target-h.ColdTolerance
+	}
+	return t >= target+h.HotTolerance
+}
+
+// ClimateThermostat is a thermostat bound to the command and state topics of a Climate. It
+// tracks the mode, set points, humidity, fan, swing and preset modes, handles the commands
+// from Home Assistant, and publishes the state. Its Controller decides the HVACAction from
+// the current temperature.
+//
+// The mode lists start as those of the Climate, or Home Assistant's defaults for the lists it
+// leaves empty.
+type ClimateThermostat struct {
+	Climate    *Climate
+	Publisher  Publisher
+	Settings   TemperatureSettings
+	Controller ThermostatController
+
+	Modes       []ClimateMode
+	FanModes    []string
+	SwingModes  []string
+	PresetModes []string
+	MinHumidity float64
+	MaxHumidity float64
+
+	// OnChange is called with the new state when a command from Home Assistant changes it.
+	// The state only changes if it succeeds. It is called with the thermostat locked, so it
+	// must not call the thermostat's methods.
+	OnChange func(ClimateState) error
+	// OnAction is called when the action changes, to switch the heating or cooling.
+	OnAction func(HVACAction)
+	// OnError is called with the errors from handling commands.
+	OnError func(error)
+
+	mu    sync.Mutex
+	state ClimateState
+}
+
+// NewClimateThermostat creates a ClimateThermostat for c that publishes with p. The set points
+// start at the initial temperature, the mode at off, and the power on.
+func NewClimateThermostat(c *Climate, p Publisher) (*ClimateThermostat, error) {
+	ts, err := c.TemperatureSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ClimateThermostat{
+		Climate:     c,
+		Publisher:   p,
+		Settings:    ts,
+		Controller:  HysteresisController{ColdTolerance: 0.3, HotTolerance: 0.3},
+		Modes:       DefaultClimateModes,
+		FanModes:    DefaultClimateFanModes,
+		SwingModes:  DefaultClimateSwingModes,
+		MinHumidity: DefaultMinHumidity,
+		MaxHumidity: DefaultMaxHumidity,
+		state: ClimateState{
+			Mode:            ClimateModeOff,
+			Action:          HVACActionOff,
+			Power:           true,
+			Temperature:     ts.Initial,
+			TemperatureLow:  ts.Initial,
+			TemperatureHigh: ts.Initial,
+		},
+	}
+	if len(c.Modes) > 0 {
+		t.Modes = c.Modes
+	}
+	if len(c.FanModes) > 0 {
+		t.FanModes = c.FanModes
+	}
+	if len(c.SwingModes) > 0 {
+		t.SwingModes = c.SwingModes
+	}
+	t.PresetModes = c.PresetModes
+	for _, h := range []struct {
+		value string
+		v     *float64
+	}{
+		{c.MinHumidity, &t.MinHumidity},
+		{c.MaxHumidity, &t.MaxHumidity},
+	} {
+		if h.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(h.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse humidity limit: %v", err)
+		}
+		*h.v = f
+	}
+
+	if len(t.FanModes) > 0 {
+		t.state.FanMode = t.FanModes[0]
+	}
+	if len(t.SwingModes) > 0 {
+		t.state.SwingMode = t.SwingModes[0]
+	}
+	t.state.TargetHumidity = t.MinHumidity
+	return t, nil
+}
+
+// State returns the current state.
+func (t *ClimateThermostat) State() ClimateState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// Publish publishes the whole state to the configured state topics.
+func (t *ClimateThermostat) Publish() error {
+	s := t.State()
+	for _, p := range []struct {
+		topic   string
+		payload string
+	}{
+		{t.Climate.ModeStateTopic, string(s.Mode)},
+		{t.Climate.ActionTopic, string(s.Action)},
+		{t.Climate.TemperatureStateTopic, t.Settings.Format(s.Temperature)},
+		{t.Climate.TemperatureLowStateTopic, t.Settings.Format(s.TemperatureLow)},
+		{t.Climate.TemperatureHighStateTopic, t.Settings.Format(s.TemperatureHigh)},
+		{t.Climate.TargetHumidityStateTopic, formatHumidity(s.TargetHumidity)},
+		{t.Climate.FanModeStateTopic, s.FanMode},
+		{t.Climate.SwingModeStateTopic, s.SwingMode},
+		{t.Climate.PresetModeStateTopic, orDefault(s.PresetMode, PresetNone)},
+	} {
+		if err := t.publish(p.topic, p.payload); err != nil {
+			return err
+		}
+	}
+
+	if s.HasCurrentTemperature {
+		if err := t.publish(t.Climate.CurrentTemperatureTopic, t.Settings.Format(s.CurrentTemperature)); err != nil {
+			return err
+		}
+	}
+	return t.publish(t.Climate.CurrentHumidityTopic, formatHumidity(s.CurrentHumidity))
+}
+
+func formatHumidity(h float64) string {
+	return strconv.FormatFloat(h, 'f', -1, 64)
+}
+
+func (t *ClimateThermostat) publish(topic, payload string) error {
+	if topic == "" {
+		return nil
+	}
+	err := t.Publisher.Publish(topic, byte(t.Climate.Qos), t.Climate.Retain, []byte(payload))
+	if err != nil {
+		return fmt.Errorf("could not publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+// update applies change to a copy of the state, and if check accepts the new state, stores
+// it, runs the controller, and publishes what changed. The change is checked and stored
+// without releasing the lock, so a concurrent update can't make the check out of date.
+func (t *ClimateThermostat) update(change func(*ClimateState), check func(ClimateState) error) error {
+	var old, s ClimateState
+	return updateState(&t.mu, func() error {
+		old, s = t.state, t.state
+		change(&s)
+		if check != nil {
+			if err := check(s); err != nil {
+				return err
+			}
+		}
+		s.Action = t.Controller.Action(s)
+		t.state = s
+		return nil
+	}, func() error {
+		if s.Action != old.Action && t.OnAction != nil {
+			t.OnAction(s.Action)
+		}
+		return t.publishChanges(old, s)
+	})
+}
+
+// updateState updates a state guarded by mu, as the controllers that run on a state do. apply
+// checks and stores the new state with mu held, and if it succeeds, changed acts on the new
+// state once mu is released, so that publishing doesn't hold up other updates.
+func updateState(mu *sync.Mutex, apply func() error, changed func() error) error {
+	mu.Lock()
+	err := apply()
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return changed()
+}
+
+// publishChanges publishes the parts of the state that changed.
+func (t *ClimateThermostat) publishChanges(old, s ClimateState) error {
+	for _, p := range []struct {
+		changed bool
+		topic   string
+		payload string
+	}{
+		{old.Mode != s.Mode, t.Climate.ModeStateTopic, string(s.Mode)},
+		{old.Action != s.Action, t.Climate.ActionTopic, string(s.Action)},
+		{old.Temperature != s.Temperature, t.Climate.TemperatureStateTopic, t.Settings.Format(s.Temperature)},
+		{old.TemperatureLow != s.TemperatureLow, t.Climate.TemperatureLowStateTopic, t.Settings.Format(s.TemperatureLow)},
+		{old.TemperatureHigh != s.TemperatureHigh, t.Climate.TemperatureHighStateTopic, t.Settings.Format(s.TemperatureHigh)},
+		{old.CurrentTemperature != s.CurrentTemperature, t.Climate.CurrentTemperatureTopic, t.Settings.Format(s.CurrentTemperature)},
+		{old.TargetHumidity != s.TargetHumidity, t.Climate.TargetHumidityStateTopic, formatHumidity(s.TargetHumidity)},
+		{old.CurrentHumidity != s.CurrentHumidity, t.Climate.CurrentHumidityTopic, formatHumidity(s.CurrentHumidity)},
+		{old.FanMode != s.FanMode, t.Climate.FanModeStateTopic, s.FanMode},
+		{old.SwingMode != s.SwingMode, t.Climate.SwingModeStateTopic, s.SwingMode},
+		{old.PresetMode != s.PresetMode, t.Climate.PresetModeStateTopic, orDefault(s.PresetMode, PresetNone)},
+	} {
+		if !p.changed {
+			continue
+		}
+		if err := t.publish(p.topic, p.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// command updates the state with a change requested by Home Assistant, which check, if it is
+// not nil, and OnChange must accept.
+func (t *ClimateThermostat) command(change func(*ClimateState), check func(ClimateState) error) error {
+	return t.update(change, func(s ClimateState) error {
+		if check != nil {
+			if err := check(s); err != nil {
+				return err
+			}
+		}
+		if t.OnChange != nil {
+			return t.OnChange(s)
+		}
+		return nil
+	})
+}
+
+// SetCurrentTemperature sets the measured temperature, and runs the controller.
+func (t *ClimateThermostat) SetCurrentTemperature(temp float64) error {
+	return t.update(func(s *ClimateState) {
+		s.CurrentTemperature = temp
+		s.HasCurrentTemperature = true
+	}, nil)
+}
+
+// SetCurrentHumidity sets the measured humidity.
+func (t *ClimateThermostat) SetCurrentHumidity(h float64) error {
+	return t.update(func(s *ClimateState) { s.CurrentHumidity = h }, nil)
+}
+
+// SetMode sets the mode, for example when it is changed on the device.
+func (t *ClimateThermostat) SetMode(mode ClimateMode) error {
+	if !containsMode(t.Modes, mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+	return t.update(func(s *ClimateState) { s.Mode = mode }, nil)
+}
+
+func containsMode(modes []ClimateMode, mode ClimateMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe subscribes the thermostat to the Climate's command topics.
+func (t *ClimateThermostat) Subscribe(s Subscriber) error {
+	for _, sub := range []struct {
+		topic   string
+		handler func(Message) error
+	}{
+		{t.Climate.ModeCommandTopic, t.HandleMode},
+		{t.Climate.PowerCommandTopic, t.HandlePower},
+		{t.Climate.TemperatureCommandTopic, t.HandleTemperature},
+		{t.Climate.TemperatureLowCommandTopic, t.HandleTemperatureLow},
+		{t.Climate.TemperatureHighCommandTopic, t.HandleTemperatureHigh},
+		{t.Climate.TargetHumidityCommandTopic, t.HandleTargetHumidity},
+		{t.Climate.FanModeCommandTopic, t.HandleFanMode},
+		{t.Climate.SwingModeCommandTopic, t.HandleSwingMode},
+		{t.Climate.PresetModeCommandTopic, t.HandlePresetMode},
+	} {
+		if sub.topic == "" {
+			continue
+		}
+		handler := sub.handler
+		err := s.Subscribe(sub.topic, byte(t.Climate.Qos), func(m Message) {
+			if err := handler(m); err != nil && t.OnError != nil {
+				t.OnError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", sub.topic, err)
+		}
+	}
+	return nil
+}
+
+// HandleMode handles a message on the ModeCommandTopic.
+func (t *ClimateThermostat) HandleMode(m Message) error {
+	mode := ClimateMode(strings.TrimSpace(string(m.Payload)))
+	if !containsMode(t.Modes, mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+	return t.command(func(s *ClimateState) { s.Mode = mode }, nil)
+}
+
+// HandlePower handles a message on the PowerCommandTopic.
+func (t *ClimateThermostat) HandlePower(m Message) error {
+	var on bool
+	switch strings.TrimSpace(string(m.Payload)) {
+	case orDefault(t.Climate.PayloadOn, "ON"):
+		on = true
+	case orDefault(t.Climate.PayloadOff, "OFF"):
+	default:
+		return fmt.Errorf("unknown power payload %q", m.Payload)
+	}
+	return t.command(func(s *ClimateState) { s.Power = on }, nil)
+}
+
+// HandleTemperature handles a message on the TemperatureCommandTopic.
+func (t *ClimateThermostat) HandleTemperature(m Message) error {
+	temp, err := t.Settings.Parse(m.Payload)
+	if err != nil {
+		return err
+	}
+	return t.command(func(s *ClimateState) { s.Temperature = temp }, nil)
+}
+
+// HandleTemperatureLow handles a message on the TemperatureLowCommandTopic.
+func (t *ClimateThermostat) HandleTemperatureLow(m Message) error {
+	temp, err := t.Settings.Parse(m.Payload)
+	if err != nil {
+		return err
+	}
+	return t.command(func(s *ClimateState) { s.TemperatureLow = temp }, func(s ClimateState) error {
+		if s.TemperatureLow > s.TemperatureHigh {
+			return fmt.Errorf("low temperature %v is above the high temperature %v", s.TemperatureLow, s.TemperatureHigh)
+		}
+		return nil
+	})
+}
+
+// HandleTemperatureHigh handles a message on the TemperatureHighCommandTopic.
+func (t *ClimateThermostat) HandleTemperatureHigh(m Message) error {
+	temp, err := t.Settings.Parse(m.Payload)
+	if err != nil {
+		return err
+	}
+	return t.command(func(s *ClimateState) { s.TemperatureHigh = temp }, func(s ClimateState) error {
+		if s.TemperatureHigh < s.TemperatureLow {
+			return fmt.Errorf("high temperature %v is below the low temperature %v", s.TemperatureHigh, s.TemperatureLow)
+		}
+		return nil
+	})
+}
+
+// HandleTargetHumidity handles a message on the TargetHumidityCommandTopic.
+func (t *ClimateThermostat) HandleTargetHumidity(m Message) error {
+	h, err := strconv.ParseFloat(strings.TrimSpace(string(m.Payload)), 64)
+	if err != nil {
+		return fmt.Errorf("could not parse humidity: %v", err)
+	}
+	if h < t.MinHumidity || h > t.MaxHumidity {
+		return fmt.Errorf("humidity %v is outside %v to %v", h, t.MinHumidity, t.MaxHumidity)
+	}
+	return t.command(func(s *ClimateState) { s.TargetHumidity = h }, nil)
+}
+
+// HandleFanMode handles a message on the FanModeCommandTopic.
+func (t *ClimateThermostat) HandleFanMode(m Message) error {
+	mode := strings.TrimSpace(string(m.Payload))
+	if !contains(t.FanModes, mode) {
+		return fmt.Errorf("fan mode %q is not supported", mode)
+	}
+	return t.command(func(s *ClimateState) { s.FanMode = mode }, nil)
+}
+
+// HandleSwingMode handles a message on the SwingModeCommandTopic.
+func (t *ClimateThermostat) HandleSwingMode(m Message) error {
+	mode := strings.TrimSpace(string(m.Payload))
+	if !contains(t.SwingModes, mode) {
+		return fmt.Errorf("swing mode %q is not supported", mode)
+	}
+	return t.command(func(s *ClimateState) { s.SwingMode = mode }, nil)
+}
+
+// HandlePresetMode handles a message on the PresetModeCommandTopic. PresetNone clears the
+// preset.
+func (t *ClimateThermostat) HandlePresetMode(m Message) error {
+	mode := strings.TrimSpace(string(m.Payload))
+	if mode == PresetNone {
+		mode = ""
+	} else if !contains(t.PresetModes, mode) {
+		return fmt.Errorf("preset mode %q is not supported", mode)
+	}
+	return t.command(func(s *ClimateState) { s.PresetMode = mode }, nil)
+}
@@ -0,0 +1,195 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHysteresisController(t *testing.T) {
+	h := HysteresisController{ColdTolerance: 0.5, HotTolerance: 0.5}
+	s := ClimateState{Mode: ClimateModeHeat, Power: true, Temperature: 20, HasCurrentTemperature: true, Action: HVACActionIdle}
+
+	for _, step := range []struct {
+		current float64
+		want    HVACAction
+	}{
+		{19.8, HVACActionIdle},
+		{19.5, HVACActionHeating},
+		{20.2, HVACActionHeating},
+		{20.5, HVACActionIdle},
+		{19.9, HVACActionIdle},
+	} {
+		s.CurrentTemperature = step.current
+		s.Action = h.Action(s)
+		if s.Action != step.want {
+			t.Errorf("heating at %v: got %s, want %s", step.current, s.Action, step.want)
+		}
+	}
+
+	s = ClimateState{Mode: ClimateModeHeatCool, Power: true, TemperatureLow: 18, TemperatureHigh: 24, HasCurrentTemperature: true}
+	for _, step := range []struct {
+		current float64
+		want    HVACAction
+	}{
+		{21, HVACActionIdle},
+		{24.5, HVACActionCooling},
+		{23.8, HVACActionCooling},
+		{23.5, HVACActionIdle},
+		{17.4, HVACActionHeating},
+	} {
+		s.CurrentTemperature = step.current
+		s.Action = h.Action(s)
+		if s.Action != step.want {
+			t.Errorf("heat_cool at %v: got %s, want %s", step.current, s.Action, step.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		s    ClimateState
+		want HVACAction
+	}{
+		{ClimateState{Mode: ClimateModeHeat}, HVACActionOff},
+		{ClimateState{Mode: ClimateModeOff, Power: true}, HVACActionOff},
+		{ClimateState{Mode: ClimateModeFanOnly, Power: true}, HVACActionFan},
+		{ClimateState{Mode: ClimateModeHeat, Power: true}, HVACActionIdle},
+	} {
+		if got := h.Action(tc.s); got != tc.want {
+			t.Errorf("%+v: got %s, want %s", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestClimateThermostat(t *testing.T) {
+	s := testSubscriber{}
+	p := &testPublisher{}
+	c := &Climate{
+		ModeCommandTopic:        "hvac/mode/set",
+		ModeStateTopic:          "hvac/mode",
+		ActionTopic:             "hvac/action",
+		TemperatureCommandTopic: "hvac/temperature/set",
+		TemperatureStateTopic:   "hvac/temperature",
+		CurrentTemperatureTopic: "hvac/current",
+		FanModeCommandTopic:     "hvac/fan/set",
+		FanModeStateTopic:       "hvac/fan",
+		PresetModeCommandTopic:  "hvac/preset/set",
+		PresetModeStateTopic:    "hvac/preset",
+		PresetModes:             []string{"eco"},
+		PowerCommandTopic:       "hvac/power",
+		Modes:                   []ClimateMode{ClimateModeHeat, ClimateModeCool},
+	}
+	bs, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("could not marshal climate: %v", err)
+	}
+	if !strings.Contains(string(bs), `"modes":["heat","cool"]`) || !strings.Contains(string(bs), `"preset_modes":["eco"]`) {
+		t.Errorf("mode lists are not marshalled as lists: %s", bs)
+	}
+
+	th, err := NewClimateThermostat(c, p)
+	if err != nil {
+		t.Fatalf("could not create thermostat: %v", err)
+	}
+	var actions []HVACAction
+	th.OnAction = func(a HVACAction) { actions = append(actions, a) }
+	var errs []error
+	th.OnError = func(err error) { errs = append(errs, err) }
+	if err := th.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	last := func(topic string) string {
+		for i := len(*p) - 1; i >= 0; i-- {
+			if (*p)[i].topic == topic {
+				return (*p)[i].payload
+			}
+		}
+		return ""
+	}
+
+	if err := th.Publish(); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+	if last("hvac/mode") != "off" || last("hvac/temperature") != "21.0" || last("hvac/fan") != "auto" || last("hvac/preset") != "none" {
+		t.Errorf("published %v", *p)
+	}
+
+	s["hvac/mode/set"](Message{Payload: []byte("heat")})
+	s["hvac/temperature/set"](Message{Payload: []byte("22")})
+	if err := th.SetCurrentTemperature(20); err != nil {
+		t.Fatalf("could not set the temperature: %v", err)
+	}
+	if last("hvac/action") != "heating" || last("hvac/current") != "20.0" || last("hvac/temperature") != "22.0" {
+		t.Errorf("published %v", *p)
+	}
+	th.SetCurrentTemperature(22.4)
+	if last("hvac/action") != "idle" {
+		t.Errorf("action is %s, want idle", last("hvac/action"))
+	}
+
+	s["hvac/power"](Message{Payload: []byte("OFF")})
+	if last("hvac/action") != "off" {
+		t.Errorf("action is %s, want off", last("hvac/action"))
+	}
+
+	s["hvac/preset/set"](Message{Payload: []byte("eco")})
+	s["hvac/fan/set"](Message{Payload: []byte("high")})
+	if st := th.State(); st.PresetMode != "eco" || st.FanMode != "high" {
+		t.Errorf("state is %+v", st)
+	}
+
+	th.OnChange = func(ClimateState) error { return errors.New("busy") }
+	s["hvac/preset/set"](Message{Payload: []byte("none")})
+	s["hvac/mode/set"](Message{Payload: []byte("cool")})
+	s["hvac/temperature/set"](Message{Payload: []byte("50")})
+	if len(errs) != 3 {
+		t.Errorf("got errors %v, want 3", errs)
+	}
+	if th.State().PresetMode != "eco" {
+		t.Errorf("preset changed when OnChange failed")
+	}
+
+	want := []HVACAction{HVACActionIdle, HVACActionHeating, HVACActionIdle, HVACActionOff}
+	if len(actions) != len(want) {
+		t.Fatalf("got actions %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("got actions %v, want %v", actions, want)
+			break
+		}
+	}
+}
+
+func TestClimateThermostatTemperatureRange(t *testing.T) {
+	th, err := NewClimateThermostat(&Climate{}, &testPublisher{})
+	if err != nil {
+		t.Fatalf("could not create thermostat: %v", err)
+	}
+	th.state.TemperatureLow, th.state.TemperatureHigh = 18, 24
+
+	// The high temperature is set while the low temperature is being checked.
+	highErr := make(chan error, 1)
+	th.OnChange = func(ClimateState) error {
+		th.OnChange = nil
+		go func() {
+			highErr <- th.HandleTemperatureHigh(Message{Payload: []byte("20")})
+		}()
+		select {
+		case err := <-highErr:
+			highErr <- err
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	}
+	lowErr := th.HandleTemperatureLow(Message{Payload: []byte("22")})
+
+	if err := <-highErr; lowErr == nil && err == nil {
+		t.Errorf("both commands succeeded, leaving %+v", th.State())
+	}
+	if s := th.State(); s.TemperatureLow > s.TemperatureHigh {
+		t.Errorf("low temperature is above the high temperature: %+v", s)
+	}
+}
@@ -0,0 +1,31 @@
+package discovery
+
+import "time"
+
+// Clock is a source of time for the helpers that simulate or time out, so that it can be
+// replaced in tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer started by a Clock.
+type Timer interface {
+	// Stop prevents the timer from firing. It returns false if the timer already fired or
+	// was stopped.
+	Stop() bool
+}
+
+// RealClock is the Clock of the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,19 @@
+package color
+
+import "math"
+
+// ScaleBrightness rescales a brightness from between 0 and from to between 0 and to, as Home
+// Assistant does for a light's brightness_scale and white_scale. The result is clamped to to.
+func ScaleBrightness(v, from, to int) int {
+	if from <= 0 {
+		return 0
+	}
+	s := int(math.Round(float64(v) * float64(to) / float64(from)))
+	if s > to {
+		return to
+	}
+	if s < 0 {
+		return 0
+	}
+	return s
+}
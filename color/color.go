@@ -0,0 +1,281 @@
+// Package color converts between the colour spaces used by Home Assistant lights.
+//
+// The conversions follow Home Assistant's own, so that values survive a round trip through
+// Home Assistant unchanged. Hue is in degrees (0 to 360) and saturation in percent (0 to 100),
+// as in the hs topics of a light. Channels are 0 to 255.
+package color
+
+import "math"
+
+// HS is a colour as hue and saturation.
+type HS struct {
+	H, S float64
+}
+
+// RGB is a colour as red, green and blue channels.
+type RGB struct {
+	R, G, B uint8
+}
+
+// RGBW is a colour as red, green, blue and white channels.
+type RGBW struct {
+	R, G, B, W uint8
+}
+
+// RGBWW is a colour as red, green, blue, cold white and warm white channels.
+type RGBWW struct {
+	R, G, B, CW, WW uint8
+}
+
+// XY is a colour as CIE 1931 xy chromaticity coordinates.
+type XY struct {
+	X, Y float64
+}
+
+// round rounds v to n decimal places.
+func round(v float64, n int) float64 {
+	p := math.Pow(10, float64(n))
+	return math.Round(v*p) / p
+}
+
+// clampChannel clamps v to a channel value, rounding it.
+func clampChannel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// HSToRGB converts a hue and saturation to an RGB colour at full brightness.
+func HSToRGB(hs HS) RGB {
+	r, g, b := hsvToRGB(hs.H/360, hs.S/100, 1)
+	return RGB{clampChannel(r * 255), clampChannel(g * 255), clampChannel(b * 255)}
+}
+
+// RGBToHS converts an RGB colour to a hue and saturation, dropping its brightness.
+func RGBToHS(c RGB) HS {
+	h, s, _ := rgbToHSV(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+	return HS{round(h*360, 3), round(s*100, 3)}
+}
+
+// hsvToRGB is Python's colorsys.hsv_to_rgb.
+func hsvToRGB(h, s, v float64) (float64, float64, float64) {
+	if s == 0 {
+		return v, v, v
+	}
+	i := math.Floor(h * 6)
+	f := h*6 - i
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+	switch int(i) % 6 {
+	case 0:
+		return v, t, p
+	case 1:
+		return q, v, p
+	case 2:
+		return p, v, t
+	case 3:
+		return p, q, v
+	case 4:
+		return t, p, v
+	}
+	return v, p, q
+}
+
+// rgbToHSV is Python's colorsys.rgb_to_hsv.
+func rgbToHSV(r, g, b float64) (float64, float64, float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	v := max
+	if max == min {
+		return 0, 0, v
+	}
+	s := (max - min) / max
+	rc := (max - r) / (max - min)
+	gc := (max - g) / (max - min)
+	bc := (max - b) / (max - min)
+	var h float64
+	switch {
+	case r == max:
+		h = bc - gc
+	case g == max:
+		h = 2 + rc - bc
+	default:
+		h = 4 + gc - rc
+	}
+	h = math.Mod(h/6, 1)
+	if h < 0 {
+		h++
+	}
+	return h, s, v
+}
+
+// RGBToXY converts an RGB colour to xy coordinates and a brightness from 0 to 255. If gamut is
+// not nil, the coordinates are clamped to it.
+func RGBToXY(c RGB, gamut *Gamut) (XY, uint8) {
+	if c == (RGB{}) {
+		return XY{}, 0
+	}
+
+	r := linear(float64(c.R) / 255)
+	g := linear(float64(c.G) / 255)
+	b := linear(float64(c.B) / 255)
+
+	// wide gamut conversion, D65
+	x := r*0.664511 + g*0.154324 + b*0.162028
+	y := r*0.283881 + g*0.668433 + b*0.047685
+	z := r*0.000088 + g*0.072310 + b*0.986039
+
+	xy := XY{x / (x + y + z), y / (x + y + z)}
+	brightness := math.Min(y, 1)
+
+	if gamut != nil {
+		xy = gamut.Clamp(xy)
+	}
+	return XY{round(xy.X, 3), round(xy.Y, 3)}, uint8(math.Round(brightness * 255))
+}
+
+// XYToRGB converts xy coordinates and a brightness from 0 to 255 to an RGB colour. If gamut
+// is not nil, the coordinates are clamped to it first.
+func XYToRGB(xy XY, brightness uint8, gamut *Gamut) RGB {
+	if gamut != nil {
+		xy = gamut.Clamp(xy)
+	}
+	if brightness == 0 {
+		return RGB{}
+	}
+
+	y := float64(brightness) / 255
+	if xy.Y == 0 {
+		xy.Y = 1e-11
+	}
+	x := y / xy.Y * xy.X
+	z := y / xy.Y * (1 - xy.X - xy.Y)
+
+	// wide gamut conversion, D65
+	r := x*1.656492 - y*0.354851 - z*0.255038
+	g := -x*0.707196 + y*1.655397 + z*0.036152
+	b := x*0.051713 - y*0.121364 + z*1.011530
+
+	r = math.Max(0, gamma(r))
+	g = math.Max(0, gamma(g))
+	b = math.Max(0, gamma(b))
+
+	if max := math.Max(r, math.Max(g, b)); max > 1 {
+		r, g, b = r/max, g/max, b/max
+	}
+	return RGB{uint8(r * 255), uint8(g * 255), uint8(b * 255)}
+}
+
+// linear removes the sRGB gamma correction.
+func linear(v float64) float64 {
+	if v > 0.04045 {
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return v / 12.92
+}
+
+// gamma applies the sRGB gamma correction.
+func gamma(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// HSToXY converts a hue and saturation to xy coordinates, clamped to gamut if it is not nil.
+func HSToXY(hs HS, gamut *Gamut) XY {
+	xy, _ := RGBToXY(HSToRGB(hs), gamut)
+	return xy
+}
+
+// XYToHS converts xy coordinates, clamped to gamut if it is not nil, to a hue and saturation.
+func XYToHS(xy XY, gamut *Gamut) HS {
+	return RGBToHS(XYToRGB(xy, 255, gamut))
+}
+
+// matchMaxScale scales out so that its largest value matches the largest value of in.
+func matchMaxScale(in []float64, out []float64) []uint8 {
+	var maxIn, maxOut float64
+	for _, v := range in {
+		maxIn = math.Max(maxIn, v)
+	}
+	for _, v := range out {
+		maxOut = math.Max(maxOut, v)
+	}
+	factor := 0.0
+	if maxOut != 0 {
+		factor = maxIn / maxOut
+	}
+	res := make([]uint8, len(out))
+	for i, v := range out {
+		res[i] = clampChannel(v * factor)
+	}
+	return res
+}
+
+// RGBToRGBW converts an RGB colour to RGBW, moving the white part of the colour to the white
+// channel.
+func RGBToRGBW(c RGB) RGBW {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	w := math.Min(r, math.Min(g, b))
+	v := matchMaxScale([]float64{r, g, b}, []float64{r - w, g - w, b - w, w})
+	return RGBW{v[0], v[1], v[2], v[3]}
+}
+
+// RGBWToRGB converts an RGBW colour to RGB, mixing the white channel into the colour.
+func RGBWToRGB(c RGBW) RGB {
+	r, g, b, w := float64(c.R), float64(c.G), float64(c.B), float64(c.W)
+	v := matchMaxScale([]float64{r, g, b, w}, []float64{r + w, g + w, b + w})
+	return RGB{v[0], v[1], v[2]}
+}
+
+// RGBToRGBWW converts an RGB colour to RGBWW, for a light with white channels between minKelvin
+// and maxKelvin. The white part of the colour is split evenly over the white channels.
+func RGBToRGBWW(c RGB, minKelvin, maxKelvin int) RGBWW {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+
+	maxMireds := float64(KelvinToMired(minKelvin))
+	minMireds := float64(KelvinToMired(maxKelvin))
+	mid := minMireds + (maxMireds-minMireds)/2
+	kelvin := 0
+	if mid != 0 {
+		kelvin = int(math.Floor(1000000 / mid))
+	}
+	wr, wg, wb := temperatureToRGB(kelvin)
+
+	level := math.Min(ratio(r, wr), math.Min(ratio(g, wg), ratio(b, wb)))
+	wr, wg, wb = wr*level, wg*level, wb*level
+	w := math.Round(level * 255)
+	v := matchMaxScale([]float64{r, g, b}, []float64{r - wr, g - wg, b - wb, w, w})
+	return RGBWW{v[0], v[1], v[2], v[3], v[4]}
+}
+
+// RGBWWToRGB converts an RGBWW colour to RGB, for a light with white channels between
+// minKelvin and maxKelvin.
+func RGBWWToRGB(c RGBWW, minKelvin, maxKelvin int) RGB {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	cw, ww := float64(c.CW), float64(c.WW)
+
+	maxMireds := float64(KelvinToMired(minKelvin))
+	minMireds := float64(KelvinToMired(maxKelvin))
+	ctRatio := 0.5
+	if cw+ww != 0 {
+		ctRatio = ww / (cw + ww)
+	}
+	mireds := minMireds + ctRatio*(maxMireds-minMireds)
+	kelvin := 0
+	if mireds != 0 {
+		kelvin = int(math.Floor(1000000 / mireds))
+	}
+	wr, wg, wb := temperatureToRGB(kelvin)
+
+	level := math.Max(cw, ww) / 255
+	v := matchMaxScale([]float64{r, g, b, cw, ww}, []float64{r + wr*level, g + wg*level, b + wb*level})
+	return RGB{v[0], v[1], v[2]}
+}
+
+func ratio(v, w float64) float64 {
+	if w == 0 {
+		return 0
+	}
+	return v / w
+}
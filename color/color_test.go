@@ -0,0 +1,179 @@
+package color
+
+import "testing"
+
+// The expected values are Home Assistant's, from the tests of its color util.
+
+func TestRGBToXY(t *testing.T) {
+	for _, tc := range []struct {
+		rgb        RGB
+		gamut      *Gamut
+		xy         XY
+		brightness uint8
+	}{
+		{RGB{0, 0, 0}, nil, XY{0, 0}, 0},
+		{RGB{255, 255, 255}, nil, XY{0.323, 0.329}, 255},
+		{RGB{0, 0, 255}, nil, XY{0.136, 0.04}, 12},
+		{RGB{0, 255, 0}, nil, XY{0.172, 0.747}, 170},
+		{RGB{255, 0, 0}, nil, XY{0.701, 0.299}, 72},
+		{RGB{128, 0, 0}, nil, XY{0.701, 0.299}, 16},
+		{RGB{255, 0, 0}, &GamutA, XY{0.7, 0.299}, 72},
+		{RGB{0, 255, 0}, &GamutA, XY{0.215, 0.711}, 170},
+		{RGB{0, 0, 255}, &GamutA, XY{0.138, 0.08}, 12},
+	} {
+		xy, b := RGBToXY(tc.rgb, tc.gamut)
+		if xy != tc.xy || b != tc.brightness {
+			t.Errorf("RGBToXY(%v) = %v, %d, want %v, %d", tc.rgb, xy, b, tc.xy, tc.brightness)
+		}
+	}
+}
+
+func TestXYToRGB(t *testing.T) {
+	for _, tc := range []struct {
+		xy         XY
+		brightness uint8
+		rgb        RGB
+	}{
+		{XY{1, 1}, 0, RGB{0, 0, 0}},
+		{XY{0.35, 0.35}, 128, RGB{194, 186, 169}},
+		{XY{0.35, 0.35}, 255, RGB{255, 243, 222}},
+		{XY{1, 0}, 255, RGB{255, 0, 60}},
+		{XY{0, 1}, 255, RGB{0, 255, 0}},
+		{XY{0, 0}, 255, RGB{0, 63, 255}},
+	} {
+		if rgb := XYToRGB(tc.xy, tc.brightness, nil); rgb != tc.rgb {
+			t.Errorf("XYToRGB(%v, %d) = %v, want %v", tc.xy, tc.brightness, rgb, tc.rgb)
+		}
+	}
+}
+
+func TestHS(t *testing.T) {
+	for _, tc := range []struct {
+		hs  HS
+		rgb RGB
+	}{
+		{HS{0, 0}, RGB{255, 255, 255}},
+		{HS{240, 100}, RGB{0, 0, 255}},
+		{HS{120, 100}, RGB{0, 255, 0}},
+		{HS{0, 100}, RGB{255, 0, 0}},
+	} {
+		if rgb := HSToRGB(tc.hs); rgb != tc.rgb {
+			t.Errorf("HSToRGB(%v) = %v, want %v", tc.hs, rgb, tc.rgb)
+		}
+		if hs := RGBToHS(tc.rgb); hs != tc.hs {
+			t.Errorf("RGBToHS(%v) = %v, want %v", tc.rgb, hs, tc.hs)
+		}
+	}
+	if rgb := HSToRGB(HS{360, 100}); rgb != (RGB{255, 0, 0}) {
+		t.Errorf("HSToRGB(360, 100) = %v", rgb)
+	}
+
+	for _, tc := range []struct {
+		xy XY
+		hs HS
+	}{
+		{XY{1, 1}, HS{47.294, 100}},
+		{XY{0.35, 0.35}, HS{38.182, 12.941}},
+		{XY{1, 0}, HS{345.882, 100}},
+		{XY{0, 1}, HS{120, 100}},
+		{XY{0, 0}, HS{225.176, 100}},
+	} {
+		if hs := XYToHS(tc.xy, nil); hs != tc.hs {
+			t.Errorf("XYToHS(%v) = %v, want %v", tc.xy, hs, tc.hs)
+		}
+	}
+
+	for _, tc := range []struct {
+		hs HS
+		xy XY
+	}{
+		{HS{180, 100}, XY{0.151, 0.343}},
+		{HS{350, 12.5}, XY{0.356, 0.321}},
+		{HS{0, 40}, XY{0.474, 0.317}},
+		{HS{360, 0}, XY{0.323, 0.329}},
+	} {
+		if xy := HSToXY(tc.hs, nil); xy != tc.xy {
+			t.Errorf("HSToXY(%v) = %v, want %v", tc.hs, xy, tc.xy)
+		}
+	}
+}
+
+func TestRGBW(t *testing.T) {
+	for _, tc := range []struct {
+		rgb  RGB
+		rgbw RGBW
+	}{
+		{RGB{0, 0, 0}, RGBW{0, 0, 0, 0}},
+		{RGB{255, 255, 255}, RGBW{0, 0, 0, 255}},
+		{RGB{255, 0, 0}, RGBW{255, 0, 0, 0}},
+		{RGB{127, 127, 127}, RGBW{0, 0, 0, 127}},
+		{RGB{255, 127, 0}, RGBW{255, 127, 0, 0}},
+	} {
+		if rgbw := RGBToRGBW(tc.rgb); rgbw != tc.rgbw {
+			t.Errorf("RGBToRGBW(%v) = %v, want %v", tc.rgb, rgbw, tc.rgbw)
+		}
+		if rgb := RGBWToRGB(tc.rgbw); rgb != tc.rgb {
+			t.Errorf("RGBWToRGB(%v) = %v, want %v", tc.rgbw, rgb, tc.rgb)
+		}
+	}
+}
+
+func TestRGBWW(t *testing.T) {
+	rgbww := RGBToRGBWW(RGB{255, 255, 255}, 2702, 6493)
+	if want := (RGBWW{0, 54, 98, 255, 255}); rgbww != want {
+		t.Errorf("RGBToRGBWW = %v, want %v", rgbww, want)
+	}
+	if rgb := RGBWWToRGB(rgbww, 2702, 6493); rgb != (RGB{255, 255, 255}) {
+		t.Errorf("RGBWWToRGB(%v) = %v", rgbww, rgb)
+	}
+	if rgbww := RGBToRGBWW(RGB{255, 0, 0}, 2702, 6493); rgbww != (RGBWW{255, 0, 0, 0, 0}) {
+		t.Errorf("RGBToRGBWW(red) = %v", rgbww)
+	}
+}
+
+func TestTemperature(t *testing.T) {
+	if k := MiredToKelvin(40); k != 25000 {
+		t.Errorf("MiredToKelvin(40) = %d", k)
+	}
+	if m := KelvinToMired(6500); m != 153 {
+		t.Errorf("KelvinToMired(6500) = %d", m)
+	}
+	if m := KelvinToMired(0); m != 0 {
+		t.Errorf("KelvinToMired(0) = %d", m)
+	}
+	if rgb := TemperatureToRGB(6600); rgb != (RGB{255, 255, 255}) {
+		t.Errorf("TemperatureToRGB(6600) = %v", rgb)
+	}
+	if TemperatureToRGB(100) != TemperatureToRGB(1000) {
+		t.Errorf("temperature was not clamped")
+	}
+}
+
+func TestGamut(t *testing.T) {
+	if !GamutA.Valid() || (Gamut{}).Valid() {
+		t.Errorf("gamut validity is wrong")
+	}
+	in := XY{0.3, 0.3}
+	if !GamutB.Contains(in) || GamutB.Clamp(in) != in {
+		t.Errorf("white is not in gamut B")
+	}
+	out := GamutB.Clamp(XY{0, 1})
+	if out != GamutB.Green {
+		t.Errorf("clamped to %v, want the green corner %v", out, GamutB.Green)
+	}
+}
+
+func TestScaleBrightness(t *testing.T) {
+	for _, tc := range []struct{ v, from, to, want int }{
+		{255, 255, 100, 100},
+		{128, 255, 100, 50},
+		{50, 100, 255, 128},
+		{300, 255, 100, 100},
+		{1, 255, 1023, 4},
+		{10, 0, 255, 0},
+	} {
+		if got := ScaleBrightness(tc.v, tc.from, tc.to); got != tc.want {
+			t.Errorf("ScaleBrightness(%d, %d, %d) = %d, want %d", tc.v, tc.from, tc.to, got, tc.want)
+		}
+	}
+}
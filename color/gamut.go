@@ -0,0 +1,65 @@
+package color
+
+import "math"
+
+// Gamut is the triangle of xy colours a light can produce.
+type Gamut struct {
+	Red, Green, Blue XY
+}
+
+// Gamuts of Philips Hue lights, which Home Assistant uses too.
+var (
+	GamutA = Gamut{XY{0.704, 0.296}, XY{0.2151, 0.7106}, XY{0.138, 0.08}}
+	GamutB = Gamut{XY{0.675, 0.322}, XY{0.409, 0.518}, XY{0.167, 0.04}}
+	GamutC = Gamut{XY{0.6915, 0.3038}, XY{0.17, 0.7}, XY{0.1532, 0.0475}}
+)
+
+// Valid returns whether the gamut is a triangle.
+func (g Gamut) Valid() bool {
+	v1 := XY{g.Green.X - g.Red.X, g.Green.Y - g.Red.Y}
+	v2 := XY{g.Blue.X - g.Red.X, g.Blue.Y - g.Red.Y}
+	return cross(v1, v2) != 0
+}
+
+// Contains returns whether xy is inside the gamut.
+func (g Gamut) Contains(xy XY) bool {
+	v1 := XY{g.Green.X - g.Red.X, g.Green.Y - g.Red.Y}
+	v2 := XY{g.Blue.X - g.Red.X, g.Blue.Y - g.Red.Y}
+	q := XY{xy.X - g.Red.X, xy.Y - g.Red.Y}
+
+	c := cross(v1, v2)
+	s := cross(q, v2) / c
+	t := cross(v1, q) / c
+	return s >= 0 && t >= 0 && s+t <= 1
+}
+
+// Clamp returns xy if it is inside the gamut, otherwise the closest colour in the gamut.
+func (g Gamut) Clamp(xy XY) XY {
+	if g.Contains(xy) {
+		return xy
+	}
+
+	closest := XY{}
+	best := math.Inf(1)
+	for _, edge := range [][2]XY{{g.Red, g.Green}, {g.Blue, g.Red}, {g.Green, g.Blue}} {
+		p := closestOnLine(edge[0], edge[1], xy)
+		if d := math.Hypot(xy.X-p.X, xy.Y-p.Y); d < best {
+			best = d
+			closest = p
+		}
+	}
+	return closest
+}
+
+func cross(a, b XY) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// closestOnLine returns the point on the line from a to b closest to p.
+func closestOnLine(a, b, p XY) XY {
+	ap := XY{p.X - a.X, p.Y - a.Y}
+	ab := XY{b.X - a.X, b.Y - a.Y}
+	t := (ap.X*ab.X + ap.Y*ab.Y) / (ab.X*ab.X + ab.Y*ab.Y)
+	t = math.Max(0, math.Min(1, t))
+	return XY{a.X + ab.X*t, a.Y + ab.Y*t}
+}
@@ -0,0 +1,61 @@
+package color
+
+import "math"
+
+// MiredToKelvin converts a colour temperature in mireds to kelvin, rounding down as Home
+// Assistant does.
+func MiredToKelvin(mireds int) int {
+	if mireds <= 0 {
+		return 0
+	}
+	return int(math.Floor(1000000 / float64(mireds)))
+}
+
+// KelvinToMired converts a colour temperature in kelvin to mireds, rounding down as Home
+// Assistant does.
+func KelvinToMired(kelvin int) int {
+	if kelvin <= 0 {
+		return 0
+	}
+	return int(math.Floor(1000000 / float64(kelvin)))
+}
+
+// TemperatureToRGB returns the RGB colour of white light at a colour temperature in kelvin,
+// which is clamped to between 1000 and 40000 K.
+func TemperatureToRGB(kelvin int) RGB {
+	r, g, b := temperatureToRGB(kelvin)
+	return RGB{clampChannel(r), clampChannel(g), clampChannel(b)}
+}
+
+// temperatureToRGB is Tanner Helland's approximation of the colour of a black body.
+func temperatureToRGB(kelvin int) (float64, float64, float64) {
+	t := math.Max(1000, math.Min(40000, float64(kelvin))) / 100
+
+	r := 255.0
+	if t > 66 {
+		r = clamp(329.698727446 * math.Pow(t-60, -0.1332047592))
+	}
+
+	var g float64
+	if t <= 66 {
+		g = clamp(99.4708025861*math.Log(t) - 161.1195681661)
+	} else {
+		g = clamp(288.1221695283 * math.Pow(t-60, -0.0755148492))
+	}
+
+	var b float64
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = clamp(138.5177312231*math.Log(t-10) - 305.0447927307)
+	}
+
+	return r, g, b
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(255, v))
+}
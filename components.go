@@ -0,0 +1,1170 @@
+package discovery
+
+// Components that support discovery. The component is part of the topic used to announce a
+// discoverable, see Announcer.
+const (
+	ComponentAlarmControlPanel = "alarm_control_panel"
+	ComponentBinarySensor      = "binary_sensor"
+	ComponentButton            = "button"
+	ComponentCamera            = "camera"
+	ComponentClimate           = "climate"
+	ComponentCover             = "cover"
+	ComponentDeviceTracker     = "device_tracker"
+	ComponentDeviceTrigger     = "device_trigger"
+	ComponentEvent             = "event"
+	ComponentFan               = "fan"
+	ComponentHumidifier        = "humidifier"
+	ComponentImage             = "image"
+	ComponentLawnMower         = "lawn_mower"
+	ComponentLight             = "light"
+	ComponentLock              = "lock"
+	ComponentNotify            = "notify"
+	ComponentNumber            = "number"
+	ComponentScene             = "scene"
+	ComponentSelect            = "select"
+	ComponentSensor            = "sensor"
+	ComponentSiren             = "siren"
+	ComponentSwitch            = "switch"
+	ComponentTag               = "tag"
+	ComponentText              = "text"
+	ComponentUpdate            = "update"
+	ComponentVacuum            = "vacuum"
+	ComponentValve             = "valve"
+	ComponentWaterHeater       = "water_heater"
+)
+
+var registry = map[string]ComponentInfo{
+	ComponentAlarmControlPanel: {
+		Name: ComponentAlarmControlPanel,
+		New:  func() Announcer { return &AlarmControlPanel{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"code",
+			"code_arm_required",
+			"code_disarm_required",
+			"code_trigger_required",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_arm_away",
+			"payload_arm_custom_bypass",
+			"payload_arm_home",
+			"payload_arm_night",
+			"payload_arm_vacation",
+			"payload_available",
+			"payload_disarm",
+			"payload_not_available",
+			"payload_trigger",
+			"platform",
+			"qos",
+			"retain",
+			"state_topic",
+			"supported_features",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentBinarySensor: {
+		Name: ComponentBinarySensor,
+		New:  func() Announcer { return &BinarySensor{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"expire_after",
+			"force_update",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"off_delay",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"platform",
+			"qos",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentButton: {
+		Name: ComponentButton,
+		New:  func() Announcer { return &Button{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_not_available",
+			"payload_press",
+			"platform",
+			"qos",
+			"retain",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentCamera: {
+		Name: ComponentCamera,
+		New:  func() Announcer { return &Camera{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"image_encoding",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"topic",
+			"unique_id",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentClimate: {
+		Name: ComponentClimate,
+		New:  func() Announcer { return &Climate{} },
+		Fields: []string{
+			"action_template",
+			"action_topic",
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"current_humidity_template",
+			"current_humidity_topic",
+			"current_temperature_template",
+			"current_temperature_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"fan_mode_command_template",
+			"fan_mode_command_topic",
+			"fan_mode_state_template",
+			"fan_mode_state_topic",
+			"fan_modes",
+			"icon",
+			"initial",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max_humidity",
+			"max_temp",
+			"min_humidity",
+			"min_temp",
+			"mode_command_template",
+			"mode_command_topic",
+			"mode_state_template",
+			"mode_state_topic",
+			"modes",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"power_command_template",
+			"power_command_topic",
+			"precision",
+			"preset_mode_command_template",
+			"preset_mode_command_topic",
+			"preset_mode_state_topic",
+			"preset_mode_value_template",
+			"preset_modes",
+			"qos",
+			"retain",
+			"swing_mode_command_template",
+			"swing_mode_command_topic",
+			"swing_mode_state_template",
+			"swing_mode_state_topic",
+			"swing_modes",
+			"target_humidity_command_template",
+			"target_humidity_command_topic",
+			"target_humidity_state_template",
+			"target_humidity_state_topic",
+			"temp_step",
+			"temperature_command_template",
+			"temperature_command_topic",
+			"temperature_high_command_template",
+			"temperature_high_command_topic",
+			"temperature_high_state_template",
+			"temperature_high_state_topic",
+			"temperature_low_command_template",
+			"temperature_low_command_topic",
+			"temperature_low_state_template",
+			"temperature_low_state_topic",
+			"temperature_state_template",
+			"temperature_state_topic",
+			"temperature_unit",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentCover: {
+		Name: ComponentCover,
+		New:  func() Announcer { return &Cover{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_close",
+			"payload_not_available",
+			"payload_open",
+			"payload_stop",
+			"platform",
+			"position_closed",
+			"position_open",
+			"position_template",
+			"position_topic",
+			"qos",
+			"retain",
+			"set_position_template",
+			"set_position_topic",
+			"state_closed",
+			"state_closing",
+			"state_open",
+			"state_opening",
+			"state_stopped",
+			"state_topic",
+			"tilt_closed_value",
+			"tilt_command_template",
+			"tilt_command_topic",
+			"tilt_max",
+			"tilt_min",
+			"tilt_opened_value",
+			"tilt_optimistic",
+			"tilt_status_template",
+			"tilt_status_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentDeviceTracker: {
+		Name: ComponentDeviceTracker,
+		New:  func() Announcer { return &DeviceTracker{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_home",
+			"payload_not_available",
+			"payload_not_home",
+			"payload_reset",
+			"platform",
+			"qos",
+			"source_type",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentDeviceTrigger: {
+		Name: ComponentDeviceTrigger,
+		New:  func() Announcer { return &DeviceTrigger{} },
+		Fields: []string{
+			"automation_type",
+			"device",
+			"payload",
+			"platform",
+			"qos",
+			"subtype",
+			"topic",
+			"type",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: false,
+	},
+	ComponentEvent: {
+		Name: ComponentEvent,
+		New:  func() Announcer { return &Event{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"event_types",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_not_available",
+			"platform",
+			"qos",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentFan: {
+		Name: ComponentFan,
+		New:  func() Announcer { return &Fan{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"direction_command_template",
+			"direction_command_topic",
+			"direction_state_topic",
+			"direction_value_template",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"oscillation_command_template",
+			"oscillation_command_topic",
+			"oscillation_state_topic",
+			"oscillation_value_template",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"payload_oscillation_off",
+			"payload_oscillation_on",
+			"payload_reset_percentage",
+			"payload_reset_preset_mode",
+			"percentage_command_template",
+			"percentage_command_topic",
+			"percentage_state_topic",
+			"percentage_value_template",
+			"platform",
+			"preset_mode_command_template",
+			"preset_mode_command_topic",
+			"preset_mode_state_topic",
+			"preset_mode_value_template",
+			"preset_modes",
+			"qos",
+			"retain",
+			"speed_range_max",
+			"speed_range_min",
+			"state_topic",
+			"state_value_template",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentHumidifier: {
+		Name: ComponentHumidifier,
+		New:  func() Announcer { return &Humidifier{} },
+		Fields: []string{
+			"action_template",
+			"action_topic",
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"current_humidity_template",
+			"current_humidity_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max_humidity",
+			"min_humidity",
+			"mode_command_template",
+			"mode_command_topic",
+			"mode_state_template",
+			"mode_state_topic",
+			"modes",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"payload_reset_humidity",
+			"payload_reset_mode",
+			"platform",
+			"qos",
+			"retain",
+			"state_topic",
+			"state_value_template",
+			"target_humidity_command_template",
+			"target_humidity_command_topic",
+			"target_humidity_state_template",
+			"target_humidity_state_topic",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentImage: {
+		Name: ComponentImage,
+		New:  func() Announcer { return &Image{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"content_type",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"image_encoding",
+			"image_topic",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"unique_id",
+			"url_template",
+			"url_topic",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentLawnMower: {
+		Name: ComponentLawnMower,
+		New:  func() Announcer { return &LawnMower{} },
+		Fields: []string{
+			"activity_state_topic",
+			"activity_value_template",
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"dock_command_template",
+			"dock_command_topic",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"pause_command_template",
+			"pause_command_topic",
+			"qos",
+			"retain",
+			"start_mowing_command_topic",
+			"start_mowing_template",
+			"unique_id",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentLight: {
+		Name: ComponentLight,
+		New:  func() Announcer { return &Light{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"brightness_command_template",
+			"brightness_command_topic",
+			"brightness_scale",
+			"brightness_state_topic",
+			"brightness_value_template",
+			"color_mode_state_topic",
+			"color_mode_value_template",
+			"color_temp_command_template",
+			"color_temp_command_topic",
+			"color_temp_state_topic",
+			"color_temp_value_template",
+			"command_topic",
+			"device",
+			"effect_command_template",
+			"effect_command_topic",
+			"effect_list",
+			"effect_state_topic",
+			"effect_value_template",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"hs_command_template",
+			"hs_command_topic",
+			"hs_state_topic",
+			"hs_value_template",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max_mireds",
+			"min_mireds",
+			"name",
+			"object_id",
+			"on_command_type",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"platform",
+			"qos",
+			"retain",
+			"rgb_command_template",
+			"rgb_command_topic",
+			"rgb_state_topic",
+			"rgb_value_template",
+			"rgbw_command_template",
+			"rgbw_command_topic",
+			"rgbw_state_topic",
+			"rgbw_value_template",
+			"rgbww_command_template",
+			"rgbww_command_topic",
+			"rgbww_state_topic",
+			"rgbww_value_template",
+			"schema",
+			"state_topic",
+			"state_value_template",
+			"unique_id",
+			"white_command_topic",
+			"white_scale",
+			"xy_command_template",
+			"xy_command_topic",
+			"xy_state_topic",
+			"xy_value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentLock: {
+		Name: ComponentLock,
+		New:  func() Announcer { return &Lock{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"code_format",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_lock",
+			"payload_not_available",
+			"payload_open",
+			"payload_reset",
+			"payload_unlock",
+			"platform",
+			"qos",
+			"retain",
+			"state_jammed",
+			"state_locked",
+			"state_locking",
+			"state_topic",
+			"state_unlocked",
+			"state_unlocking",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentNotify: {
+		Name: ComponentNotify,
+		New:  func() Announcer { return &Notify{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_not_available",
+			"platform",
+			"qos",
+			"retain",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentNumber: {
+		Name: ComponentNumber,
+		New:  func() Announcer { return &Number{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max",
+			"min",
+			"mode",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_reset",
+			"platform",
+			"qos",
+			"retain",
+			"state_topic",
+			"step",
+			"unique_id",
+			"unit_of_measurement",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentScene: {
+		Name: ComponentScene,
+		New:  func() Announcer { return &Scene{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_not_available",
+			"payload_on",
+			"platform",
+			"qos",
+			"retain",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+	ComponentSelect: {
+		Name: ComponentSelect,
+		New:  func() Announcer { return &Select{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"options",
+			"platform",
+			"qos",
+			"retain",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentSensor: {
+		Name: ComponentSensor,
+		New:  func() Announcer { return &Sensor{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"expire_after",
+			"force_update",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"last_reset_value_template",
+			"name",
+			"object_id",
+			"options",
+			"payload_available",
+			"payload_not_available",
+			"platform",
+			"qos",
+			"state_class",
+			"state_topic",
+			"suggested_display_precision",
+			"unique_id",
+			"unit_of_measurement",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentSiren: {
+		Name: ComponentSiren,
+		New:  func() Announcer { return &Siren{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"available_tones",
+			"command_off_template",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"platform",
+			"qos",
+			"retain",
+			"state_off",
+			"state_on",
+			"state_topic",
+			"state_value_template",
+			"support_duration",
+			"support_volume_set",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentSwitch: {
+		Name: ComponentSwitch,
+		New:  func() Announcer { return &Switch{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"platform",
+			"qos",
+			"retain",
+			"state_off",
+			"state_on",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentTag: {
+		Name: ComponentTag,
+		New:  func() Announcer { return &Tag{} },
+		Fields: []string{
+			"device",
+			"topic",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: false,
+	},
+	ComponentText: {
+		Name: ComponentText,
+		New:  func() Announcer { return &Text{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max",
+			"min",
+			"mode",
+			"name",
+			"object_id",
+			"pattern",
+			"payload_available",
+			"payload_not_available",
+			"platform",
+			"qos",
+			"retain",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentUpdate: {
+		Name: ComponentUpdate,
+		New:  func() Announcer { return &Update{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_topic",
+			"device",
+			"device_class",
+			"display_precision",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"latest_version_template",
+			"latest_version_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_install",
+			"payload_not_available",
+			"platform",
+			"qos",
+			"release_summary",
+			"release_url",
+			"retain",
+			"state_topic",
+			"title",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentVacuum: {
+		Name: ComponentVacuum,
+		New:  func() Announcer { return &Vacuum{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_topic",
+			"device",
+			"encoding",
+			"fan_speed_list",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"payload_available",
+			"payload_clean_spot",
+			"payload_locate",
+			"payload_not_available",
+			"payload_pause",
+			"payload_return_to_base",
+			"payload_start",
+			"payload_stop",
+			"platform",
+			"qos",
+			"retain",
+			"send_command_topic",
+			"set_fan_speed_topic",
+			"state_topic",
+			"supported_features",
+			"unique_id",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentValve: {
+		Name: ComponentValve,
+		New:  func() Announcer { return &Valve{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"command_template",
+			"command_topic",
+			"device",
+			"device_class",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_close",
+			"payload_not_available",
+			"payload_open",
+			"payload_stop",
+			"platform",
+			"position_closed",
+			"position_open",
+			"qos",
+			"reports_position",
+			"retain",
+			"state_closed",
+			"state_closing",
+			"state_open",
+			"state_opening",
+			"state_topic",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  true,
+		HasStateTopic:    true,
+		SupportsUniqueID: true,
+	},
+	ComponentWaterHeater: {
+		Name: ComponentWaterHeater,
+		New:  func() Announcer { return &WaterHeater{} },
+		Fields: []string{
+			"availability",
+			"availability_mode",
+			"availability_template",
+			"availability_topic",
+			"current_temperature_template",
+			"current_temperature_topic",
+			"device",
+			"enabled_by_default",
+			"encoding",
+			"entity_category",
+			"entity_picture",
+			"icon",
+			"initial",
+			"json_attributes_template",
+			"json_attributes_topic",
+			"max_temp",
+			"min_temp",
+			"mode_command_template",
+			"mode_command_topic",
+			"mode_state_template",
+			"mode_state_topic",
+			"modes",
+			"name",
+			"object_id",
+			"optimistic",
+			"payload_available",
+			"payload_not_available",
+			"payload_off",
+			"payload_on",
+			"platform",
+			"power_command_template",
+			"power_command_topic",
+			"precision",
+			"qos",
+			"retain",
+			"temperature_command_template",
+			"temperature_command_topic",
+			"temperature_state_template",
+			"temperature_state_topic",
+			"temperature_unit",
+			"unique_id",
+			"value_template",
+		},
+		HasCommandTopic:  false,
+		HasStateTopic:    false,
+		SupportsUniqueID: true,
+	},
+}
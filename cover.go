@@ -98,11 +98,11 @@ type Cover struct {
 
 	// Number which represents closed position
 	// Default: 0
-	PositionClosed int `json:"position_closed,omitempty"`
+	PositionClosed *int `json:"position_closed,omitempty"`
 
 	// Number which represents open position
 	// Default: 100
-	PositionOpen int `json:"position_open,omitempty"`
+	PositionOpen *int `json:"position_open,omitempty"`
 
 	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) that can be used to extract the payload for the `position_topic` topic. Within the template the following variables are available: `entity_id`, `position_open`; `position_closed`; `tilt_min`; `tilt_max`. The `entity_id` can be used to reference the entity's attributes with help of the [states](/docs/configuration/templating/#states) template function
 	// Default: <no value>
@@ -166,11 +166,11 @@ type Cover struct {
 
 	// The maximum tilt value
 	// Default: 100
-	TiltMax int `json:"tilt_max,omitempty"`
+	TiltMax *int `json:"tilt_max,omitempty"`
 
 	// The minimum tilt value
 	// Default: 0
-	TiltMin int `json:"tilt_min,omitempty"`
+	TiltMin *int `json:"tilt_min,omitempty"`
 
 	// The value that will be sent on an `open_cover_tilt` command
 	// Default: 100
@@ -199,7 +199,9 @@ type Cover struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Cover
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Cover
 func (d *Cover) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/cover/%s/config"
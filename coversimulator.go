@@ -0,0 +1,270 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUpdateInterval is how often a CoverSimulator publishes the estimated position while
+// the cover moves, if UpdateInterval is not set.
+const DefaultUpdateInterval = time.Second
+
+// CoverSimulator estimates the position and tilt of a Cover that doesn't report them, from the
+// time it takes to travel. It handles the commands from Home Assistant, passing them on to the
+// device, and publishes the state, position and tilt while the cover moves.
+type CoverSimulator struct {
+	Cover     *Cover
+	Publisher Publisher
+	// Model is the position of the cover, between PositionClosed and PositionOpen.
+	Model *PositionModel
+	// Tilt is the tilt of the cover, between TiltMin and TiltMax.
+	Tilt *PositionModel
+
+	// TravelTime is the time the cover takes to go from closed to open.
+	TravelTime time.Duration
+	// TiltTime is the time the cover takes to tilt from TiltMin to TiltMax. If it is zero the
+	// cover tilts immediately.
+	TiltTime time.Duration
+	// UpdateInterval is how often the position is published while moving.
+	UpdateInterval time.Duration
+	Clock          Clock
+
+	// OnCommand is called with the commands from Home Assistant, to move the cover. The
+	// simulation only starts if it succeeds.
+	OnCommand func(PositionCommand) error
+	// OnTilt is called with the tilt commands from Home Assistant, as a percentage.
+	OnTilt func(float64) error
+	// OnError is called with the errors from handling commands and publishing.
+	OnError func(error)
+
+	mu       sync.Mutex
+	position travel
+	tilt     travel
+}
+
+// travel is a movement of a cover, or of its tilt.
+type travel struct {
+	model *PositionModel
+	from  float64
+	start time.Time
+	timer Timer
+	// gen identifies the movement, so that a timer of a previous one is ignored.
+	gen int
+}
+
+// NewCoverSimulator creates a CoverSimulator for c that publishes with p, for a cover that
+// takes travelTime to open. The cover starts closed.
+func NewCoverSimulator(c *Cover, p Publisher, travelTime time.Duration) *CoverSimulator {
+	tilt := &PositionModel{
+		PositionOpen:   intOrDefault(c.TiltMax, 100),
+		PositionClosed: intOrDefault(c.TiltMin, 0),
+		motion:         MotionStopped,
+	}
+
+	s := &CoverSimulator{
+		Cover:          c,
+		Publisher:      p,
+		Model:          NewCoverModel(c),
+		Tilt:           tilt,
+		TravelTime:     travelTime,
+		UpdateInterval: DefaultUpdateInterval,
+		Clock:          RealClock,
+	}
+	// the position is simulated, so it is always known
+	s.Model.ReportsPosition = true
+	s.position.model = s.Model
+	s.tilt.model = s.Tilt
+	return s
+}
+
+// Subscribe subscribes the simulator to the Cover's command, set position and tilt command
+// topics.
+func (s *CoverSimulator) Subscribe(sub Subscriber) error {
+	for _, t := range []struct {
+		topic   string
+		handler func(Message) error
+	}{
+		{s.Cover.CommandTopic, s.HandleCommand},
+		{s.Cover.SetPositionTopic, s.HandleCommand},
+		{s.Cover.TiltCommandTopic, s.HandleTilt},
+	} {
+		if t.topic == "" {
+			continue
+		}
+		handler := t.handler
+		err := sub.Subscribe(t.topic, byte(s.Cover.Qos), func(m Message) {
+			if err := handler(m); err != nil {
+				s.error(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", t.topic, err)
+		}
+	}
+	return nil
+}
+
+func (s *CoverSimulator) error(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+// HandleCommand handles a message on the CommandTopic or SetPositionTopic.
+func (s *CoverSimulator) HandleCommand(m Message) error {
+	c, err := s.Model.Decode(m.Payload)
+	if err != nil {
+		return err
+	}
+	return s.Command(c)
+}
+
+// HandleTilt handles a message on the TiltCommandTopic, which is a tilt between TiltMin and
+// TiltMax.
+func (s *CoverSimulator) HandleTilt(m Message) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(m.Payload)), 64)
+	if err != nil {
+		return fmt.Errorf("could not parse tilt %q: %v", m.Payload, err)
+	}
+	return s.SetTilt(s.Tilt.percent(v))
+}
+
+// Command passes c to OnCommand, then starts or stops simulating the movement.
+func (s *CoverSimulator) Command(c PositionCommand) error {
+	if s.OnCommand != nil {
+		if err := s.OnCommand(c); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	ms := s.move(&s.position, s.TravelTime, c)
+	s.mu.Unlock()
+	return s.send(ms)
+}
+
+// SetTilt passes the tilt, as a percentage, to OnTilt, then starts simulating the tilt.
+func (s *CoverSimulator) SetTilt(percent float64) error {
+	if s.OnTilt != nil {
+		if err := s.OnTilt(percent); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	ms := s.move(&s.tilt, s.TiltTime, PositionCommand{Kind: PositionCommandSetPosition, Position: percent})
+	s.mu.Unlock()
+	return s.send(ms)
+}
+
+// move updates the estimate of the current movement of t, and starts the next one. It returns
+// the messages to publish once the lock is released.
+func (s *CoverSimulator) move(t *travel, d time.Duration, c PositionCommand) []simulatorMessage {
+	s.estimate(t, d)
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.gen++
+
+	t.model.Apply(c)
+	if d <= 0 {
+		t.model.SetPosition(t.model.Target())
+	}
+	t.from = t.model.Position()
+	t.start = s.Clock.Now()
+
+	s.schedule(t, d)
+	return s.messages(t)
+}
+
+// estimate sets the position of t from the time it has been moving.
+func (s *CoverSimulator) estimate(t *travel, d time.Duration) {
+	motion := t.model.Motion()
+	if motion == MotionStopped {
+		return
+	}
+
+	moved := 100.0
+	if d > 0 {
+		moved = 100 * float64(s.Clock.Now().Sub(t.start)) / float64(d)
+	}
+	target := t.model.Target()
+	if motion == MotionOpening {
+		t.model.SetPosition(math.Min(t.from+moved, target))
+	} else {
+		t.model.SetPosition(math.Max(t.from-moved, target))
+	}
+}
+
+// schedule schedules the next update of t while it is moving, either after the update
+// interval or when it reaches the target.
+func (s *CoverSimulator) schedule(t *travel, d time.Duration) {
+	if t.model.Motion() == MotionStopped {
+		t.timer = nil
+		return
+	}
+
+	next := s.UpdateInterval
+	if next <= 0 {
+		next = DefaultUpdateInterval
+	}
+	remaining := time.Duration(math.Ceil(math.Abs(t.model.Target()-t.model.Position()) / 100 * float64(d)))
+	if remaining < next {
+		next = remaining
+	}
+
+	gen := t.gen
+	t.timer = s.Clock.AfterFunc(next, func() {
+		s.mu.Lock()
+		if t.gen != gen {
+			s.mu.Unlock()
+			return
+		}
+		s.estimate(t, d)
+		s.schedule(t, d)
+		ms := s.messages(t)
+		s.mu.Unlock()
+
+		if err := s.send(ms); err != nil {
+			s.error(err)
+		}
+	})
+}
+
+// simulatorMessage is a payload for one of the Cover's state topics.
+type simulatorMessage struct {
+	topic   string
+	payload string
+}
+
+// messages returns the state and position, or the tilt, to publish. It must be called with the
+// lock held.
+func (s *CoverSimulator) messages(t *travel) []simulatorMessage {
+	if t == &s.tilt {
+		return []simulatorMessage{
+			{s.Cover.TiltStatusTopic, strconv.Itoa(s.Tilt.Scale(s.Tilt.Position()))},
+		}
+	}
+	return []simulatorMessage{
+		{s.Cover.StateTopic, s.Model.State()},
+		{s.Cover.PositionTopic, strconv.Itoa(s.Model.Scale(s.Model.Position()))},
+	}
+}
+
+// send publishes the messages. It must be called without the lock held.
+func (s *CoverSimulator) send(ms []simulatorMessage) error {
+	for _, m := range ms {
+		if m.topic == "" {
+			continue
+		}
+		err := s.Publisher.Publish(m.topic, byte(s.Cover.Qos), s.Cover.Retain, []byte(m.payload))
+		if err != nil {
+			return fmt.Errorf("could not publish to %s: %v", m.topic, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoverSimulator(t *testing.T) {
+	clock := newTestClock()
+	s := testSubscriber{}
+	p := &testPublisher{}
+	tiltMin, tiltMax := -90, 90
+	c := &Cover{
+		CommandTopic:     "blind/set",
+		SetPositionTopic: "blind/position/set",
+		StateTopic:       "blind/state",
+		PositionTopic:    "blind/position",
+		TiltCommandTopic: "blind/tilt/set",
+		TiltStatusTopic:  "blind/tilt",
+		TiltMin:          &tiltMin,
+		TiltMax:          &tiltMax,
+	}
+	cs := NewCoverSimulator(c, p, 10*time.Second)
+	cs.TiltTime = 2 * time.Second
+	cs.UpdateInterval = 4 * time.Second
+	cs.Clock = clock
+	var commands []PositionCommand
+	cs.OnCommand = func(c PositionCommand) error {
+		commands = append(commands, c)
+		return nil
+	}
+	var errs []error
+	cs.OnError = func(err error) { errs = append(errs, err) }
+	if err := cs.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	last := func(topic string) string {
+		for i := len(*p) - 1; i >= 0; i-- {
+			if (*p)[i].topic == topic {
+				return (*p)[i].payload
+			}
+		}
+		return ""
+	}
+
+	s["blind/set"](Message{Payload: []byte("OPEN")})
+	if last("blind/state") != "opening" || last("blind/position") != "0" {
+		t.Errorf("published %v", *p)
+	}
+
+	clock.Advance(4 * time.Second)
+	if last("blind/position") != "40" || last("blind/state") != "opening" {
+		t.Errorf("position is %s, want 40", last("blind/position"))
+	}
+
+	clock.Advance(time.Second)
+	s["blind/set"](Message{Payload: []byte("STOP")})
+	if last("blind/position") != "50" || last("blind/state") != "stopped" {
+		t.Errorf("stopped at %s %s, want 50 stopped", last("blind/position"), last("blind/state"))
+	}
+	n := len(*p)
+	clock.Advance(time.Minute)
+	if len(*p) != n {
+		t.Errorf("published after stopping: %v", (*p)[n:])
+	}
+
+	s["blind/position/set"](Message{Payload: []byte("20")})
+	if last("blind/state") != "closing" {
+		t.Errorf("state is %s, want closing", last("blind/state"))
+	}
+	clock.Advance(2 * time.Second)
+	clock.Advance(time.Second)
+	if last("blind/position") != "20" || last("blind/state") != "stopped" {
+		t.Errorf("stopped at %s %s, want 20 stopped", last("blind/position"), last("blind/state"))
+	}
+
+	s["blind/set"](Message{Payload: []byte("CLOSE")})
+	clock.Advance(10 * time.Second)
+	if last("blind/position") != "0" || last("blind/state") != "closed" {
+		t.Errorf("stopped at %s %s, want 0 closed", last("blind/position"), last("blind/state"))
+	}
+
+	s["blind/tilt/set"](Message{Payload: []byte("90")})
+	if last("blind/tilt") != "-90" {
+		t.Errorf("tilt is %s, want -90", last("blind/tilt"))
+	}
+	clock.Advance(2 * time.Second)
+	if last("blind/tilt") != "90" {
+		t.Errorf("tilt is %s, want 90", last("blind/tilt"))
+	}
+
+	s["blind/set"](Message{Payload: []byte("UP")})
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want 1", errs)
+	}
+	if len(commands) != 4 {
+		t.Errorf("got %d commands, want 4", len(commands))
+	}
+}
+
+// lockingPublisher takes the simulator's lock while publishing, which only succeeds if the
+// simulator has released it.
+type lockingPublisher struct {
+	testPublisher
+	cs *CoverSimulator
+}
+
+func (p *lockingPublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	p.cs.mu.Lock()
+	p.cs.mu.Unlock()
+	return p.testPublisher.Publish(topic, qos, retained, payload)
+}
+
+func TestCoverSimulatorTilt(t *testing.T) {
+	p := &lockingPublisher{}
+	cs := NewCoverSimulator(&Cover{TiltStatusTopic: "blind/tilt"}, p, time.Second)
+	p.cs = cs
+	if cs.Tilt.PositionClosed != 0 || cs.Tilt.PositionOpen != 100 {
+		t.Errorf("tilt range is %d to %d, want Home Assistant's 0 to 100", cs.Tilt.PositionClosed, cs.Tilt.PositionOpen)
+	}
+
+	done := make(chan error)
+	go func() { done <- cs.SetTilt(40) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("could not tilt: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("published while holding the lock")
+	}
+	if len(p.testPublisher) != 1 || p.testPublisher[0].payload != "40" {
+		t.Errorf("published %+v", p.testPublisher)
+	}
+}
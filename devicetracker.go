@@ -97,7 +97,9 @@ type DeviceTracker struct {
 
 // AnnounceTopic returns the topic to announce the discoverable DeviceTracker
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the DeviceTracker
 func (d *DeviceTracker) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/device_tracker/%s/config"
@@ -43,7 +43,9 @@ type DeviceTrigger struct {
 
 // AnnounceTopic returns the topic to announce the discoverable DeviceTrigger
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the DeviceTrigger
 func (d *DeviceTrigger) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/device_trigger/%s/config"
@@ -0,0 +1,237 @@
+package discovery
+
+// AvailabilityMode values control when an entity with several availability topics is available.
+const (
+	AvailabilityModeAll    = "all"
+	AvailabilityModeAny    = "any"
+	AvailabilityModeLatest = "latest"
+)
+
+// EntityCategory values classify entities that are not the primary controls of a device.
+const (
+	EntityCategoryConfig     = "config"
+	EntityCategoryDiagnostic = "diagnostic"
+)
+
+// AlarmState is a state of an AlarmControlPanel.
+type AlarmState string
+
+const (
+	AlarmStateDisarmed          AlarmState = "disarmed"
+	AlarmStateArmedHome         AlarmState = "armed_home"
+	AlarmStateArmedAway         AlarmState = "armed_away"
+	AlarmStateArmedNight        AlarmState = "armed_night"
+	AlarmStateArmedVacation     AlarmState = "armed_vacation"
+	AlarmStateArmedCustomBypass AlarmState = "armed_custom_bypass"
+	AlarmStatePending           AlarmState = "pending"
+	AlarmStateTriggered         AlarmState = "triggered"
+	AlarmStateArming            AlarmState = "arming"
+	AlarmStateDisarming         AlarmState = "disarming"
+)
+
+// BinarySensorDeviceClass values are the device classes of a BinarySensor.
+const (
+	BinarySensorDeviceClassBattery         = "battery"
+	BinarySensorDeviceClassBatteryCharging = "battery_charging"
+	BinarySensorDeviceClassCarbonMonoxide  = "carbon_monoxide"
+	BinarySensorDeviceClassCold            = "cold"
+	BinarySensorDeviceClassConnectivity    = "connectivity"
+	BinarySensorDeviceClassDoor            = "door"
+	BinarySensorDeviceClassGarageDoor      = "garage_door"
+	BinarySensorDeviceClassGas             = "gas"
+	BinarySensorDeviceClassHeat            = "heat"
+	BinarySensorDeviceClassLight           = "light"
+	BinarySensorDeviceClassLock            = "lock"
+	BinarySensorDeviceClassMoisture        = "moisture"
+	BinarySensorDeviceClassMotion          = "motion"
+	BinarySensorDeviceClassMoving          = "moving"
+	BinarySensorDeviceClassOccupancy       = "occupancy"
+	BinarySensorDeviceClassOpening         = "opening"
+	BinarySensorDeviceClassPlug            = "plug"
+	BinarySensorDeviceClassPower           = "power"
+	BinarySensorDeviceClassPresence        = "presence"
+	BinarySensorDeviceClassProblem         = "problem"
+	BinarySensorDeviceClassRunning         = "running"
+	BinarySensorDeviceClassSafety          = "safety"
+	BinarySensorDeviceClassSmoke           = "smoke"
+	BinarySensorDeviceClassSound           = "sound"
+	BinarySensorDeviceClassTamper          = "tamper"
+	BinarySensorDeviceClassUpdate          = "update"
+	BinarySensorDeviceClassVibration       = "vibration"
+	BinarySensorDeviceClassWindow          = "window"
+)
+
+// ButtonDeviceClass values are the device classes of a Button.
+const (
+	ButtonDeviceClassIdentify = "identify"
+	ButtonDeviceClassRestart  = "restart"
+	ButtonDeviceClassUpdate   = "update"
+)
+
+// ClimateMode is an HVAC mode of a Climate.
+type ClimateMode string
+
+const (
+	ClimateModeAuto     ClimateMode = "auto"
+	ClimateModeOff      ClimateMode = "off"
+	ClimateModeCool     ClimateMode = "cool"
+	ClimateModeHeat     ClimateMode = "heat"
+	ClimateModeDry      ClimateMode = "dry"
+	ClimateModeFanOnly  ClimateMode = "fan_only"
+	ClimateModeHeatCool ClimateMode = "heat_cool"
+)
+
+// HVACAction is what a Climate is currently doing, published to its ActionTopic.
+type HVACAction string
+
+const (
+	HVACActionOff        HVACAction = "off"
+	HVACActionHeating    HVACAction = "heating"
+	HVACActionCooling    HVACAction = "cooling"
+	HVACActionDrying     HVACAction = "drying"
+	HVACActionIdle       HVACAction = "idle"
+	HVACActionFan        HVACAction = "fan"
+	HVACActionPreheating HVACAction = "preheating"
+	HVACActionDefrosting HVACAction = "defrosting"
+)
+
+// CoverDeviceClass values are the device classes of a Cover.
+const (
+	CoverDeviceClassAwning  = "awning"
+	CoverDeviceClassBlind   = "blind"
+	CoverDeviceClassCurtain = "curtain"
+	CoverDeviceClassDamper  = "damper"
+	CoverDeviceClassDoor    = "door"
+	CoverDeviceClassGarage  = "garage"
+	CoverDeviceClassGate    = "gate"
+	CoverDeviceClassShade   = "shade"
+	CoverDeviceClassShutter = "shutter"
+	CoverDeviceClassWindow  = "window"
+)
+
+// EventDeviceClass values are the device classes of an Event.
+const (
+	EventDeviceClassButton   = "button"
+	EventDeviceClassDoorbell = "doorbell"
+	EventDeviceClassMotion   = "motion"
+)
+
+// HumidifierDeviceClass values are the device classes of a Humidifier.
+const (
+	HumidifierDeviceClassHumidifier   = "humidifier"
+	HumidifierDeviceClassDehumidifier = "dehumidifier"
+)
+
+// HumidifierAction is what a Humidifier is currently doing, published to its ActionTopic.
+type HumidifierAction string
+
+const (
+	HumidifierActionOff         HumidifierAction = "off"
+	HumidifierActionHumidifying HumidifierAction = "humidifying"
+	HumidifierActionDrying      HumidifierAction = "drying"
+	HumidifierActionIdle        HumidifierAction = "idle"
+)
+
+// LawnMowerActivity is the activity state of a LawnMower.
+type LawnMowerActivity string
+
+const (
+	LawnMowerActivityDocked    LawnMowerActivity = "docked"
+	LawnMowerActivityError     LawnMowerActivity = "error"
+	LawnMowerActivityMowing    LawnMowerActivity = "mowing"
+	LawnMowerActivityPaused    LawnMowerActivity = "paused"
+	LawnMowerActivityReturning LawnMowerActivity = "returning"
+)
+
+// LockState is a state of a Lock, published as the matching State payload of the Lock.
+type LockState string
+
+const (
+	LockStateLocked    LockState = "locked"
+	LockStateLocking   LockState = "locking"
+	LockStateUnlocked  LockState = "unlocked"
+	LockStateUnlocking LockState = "unlocking"
+	LockStateJammed    LockState = "jammed"
+)
+
+// NumberMode values control how a Number is displayed in the UI.
+const (
+	NumberModeAuto   = "auto"
+	NumberModeBox    = "box"
+	NumberModeSlider = "slider"
+)
+
+// TextMode values control how a Text is displayed in the UI.
+const (
+	TextModeText     = "text"
+	TextModePassword = "password"
+)
+
+// UpdateDeviceClass values are the device classes of an Update.
+const (
+	UpdateDeviceClassFirmware = "firmware"
+)
+
+// VacuumFeature values are the features a Vacuum can list in its SupportedFeatures.
+const (
+	VacuumFeatureStart       = "start"
+	VacuumFeatureStop        = "stop"
+	VacuumFeaturePause       = "pause"
+	VacuumFeatureReturnHome  = "return_home"
+	VacuumFeatureBattery     = "battery"
+	VacuumFeatureStatus      = "status"
+	VacuumFeatureLocate      = "locate"
+	VacuumFeatureCleanSpot   = "clean_spot"
+	VacuumFeatureFanSpeed    = "fan_speed"
+	VacuumFeatureSendCommand = "send_command"
+)
+
+// VacuumState is a state of a Vacuum, published in its state JSON.
+type VacuumState string
+
+const (
+	VacuumStateCleaning  VacuumState = "cleaning"
+	VacuumStateDocked    VacuumState = "docked"
+	VacuumStatePaused    VacuumState = "paused"
+	VacuumStateIdle      VacuumState = "idle"
+	VacuumStateReturning VacuumState = "returning"
+	VacuumStateError     VacuumState = "error"
+)
+
+// ValveDeviceClass values are the device classes of a Valve.
+const (
+	ValveDeviceClassGas   = "gas"
+	ValveDeviceClassWater = "water"
+)
+
+// WaterHeaterMode is an operation mode of a WaterHeater.
+type WaterHeaterMode string
+
+const (
+	WaterHeaterModeEco         WaterHeaterMode = "eco"
+	WaterHeaterModeElectric    WaterHeaterMode = "electric"
+	WaterHeaterModeGas         WaterHeaterMode = "gas"
+	WaterHeaterModeHeatPump    WaterHeaterMode = "heat_pump"
+	WaterHeaterModeHighDemand  WaterHeaterMode = "high_demand"
+	WaterHeaterModePerformance WaterHeaterMode = "performance"
+	WaterHeaterModeOff         WaterHeaterMode = "off"
+)
+
+// TemperatureUnit values are the temperature units of a Climate or WaterHeater.
+const (
+	TemperatureUnitC = "C"
+	TemperatureUnitF = "F"
+)
+
+// SensorStateClass values are the state classes of a Sensor.
+const (
+	SensorStateClassMeasurement     = "measurement"
+	SensorStateClassTotal           = "total"
+	SensorStateClassTotalIncreasing = "total_increasing"
+)
+
+// SwitchDeviceClass values are the device classes of a Switch.
+const (
+	SwitchDeviceClassOutlet = "outlet"
+	SwitchDeviceClassSwitch = "switch"
+)
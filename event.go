@@ -0,0 +1,119 @@
+package discovery
+
+import "fmt"
+
+type Event struct {
+
+	// A list of MQTT topics subscribed to receive availability (online/offline) updates. Must not be used together with `availability_topic`
+	// Default: <no value>
+	Availability []Availability `json:"availability,omitempty"`
+
+	// When `availability` is configured, this controls the conditions needed to set the entity to `available`. Valid entries are `all`, `any`, and `latest`. If set to `all`, `payload_available` must be received on all configured availability topics before the entity is marked as online. If set to `any`, `payload_available` must be received on at least one configured availability topic before the entity is marked as online. If set to `latest`, the last `payload_available` or `payload_not_available` received on any configured availability topic controls the availability
+	// Default: latest
+	AvailabilityMode string `json:"availability_mode,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract device's availability from the `availability_topic`. To determine the devices's availability result of this template will be compared to `payload_available` and `payload_not_available`
+	// Default: <no value>
+	AvailabilityTemplate string `json:"availability_template,omitempty"`
+
+	// The MQTT topic subscribed to receive availability (online/offline) updates. Must not be used together with `availability`
+	// Default: <no value>
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	// Information about the device this event is a part of to tie it into the [device registry](https://developers.home-assistant.io/docs/en/device_registry_index.html). Only works when [`unique_id`](#unique_id) is set. At least one of identifiers or connections must be present to identify the device
+	// Default: <no value>
+	Device *Device `json:"device,omitempty"`
+
+	// The [type/class](/integrations/event/#device-class) of the event to set the icon in the frontend. The `device_class` can be `null`
+	// Default: <no value>
+	DeviceClass string `json:"device_class,omitempty"`
+
+	// Flag which defines if the entity should be enabled when first added
+	// Default: true
+	EnabledByDefault bool `json:"enabled_by_default,omitempty"`
+
+	// The encoding of the payloads received and published messages. Set to `""` to disable decoding of incoming payload
+	// Default: utf-8
+	Encoding string `json:"encoding,omitempty"`
+
+	// The [category](https://developers.home-assistant.io/docs/core/entity#generic-properties) of the entity
+	// Default: <no value>
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	// Picture URL for the entity
+	// Default: <no value>
+	EntityPicture string `json:"entity_picture,omitempty"`
+
+	// A list of valid `event_type` strings
+	// Default: <no value>
+	EventTypes []string `json:"event_types"`
+
+	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
+	// Default: <no value>
+	Icon string `json:"icon,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the JSON dictionary from messages received on the `json_attributes_topic`. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-template-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTemplate string `json:"json_attributes_template,omitempty"`
+
+	// The MQTT topic subscribed to receive a JSON dictionary payload and then set as sensor attributes. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-topic-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTopic string `json:"json_attributes_topic,omitempty"`
+
+	// The name to use when displaying this event. Can be set to `null` if only the device name is relevant
+	// Default: MQTT Event
+	Name string `json:"name,omitempty"`
+
+	// Used instead of `name` for automatic generation of `entity_id
+	// Default: <no value>
+	ObjectId string `json:"object_id,omitempty"`
+
+	// The payload that represents the available state
+	// Default: online
+	PayloadAvailable string `json:"payload_available,omitempty"`
+
+	// The payload that represents the unavailable state
+	// Default: offline
+	PayloadNotAvailable string `json:"payload_not_available,omitempty"`
+
+	// Must be `event`. Only allowed and required in [MQTT auto discovery device messages](/integrations/mqtt/#device-discovery-payload)
+	// Default: <no value>
+	Platform string `json:"platform"`
+
+	// The maximum QoS level to be used when receiving and publishing messages
+	// Default: 0
+	Qos int `json:"qos,omitempty"`
+
+	// The MQTT topic subscribed to receive JSON event payloads. The JSON payload should contain the `event_type` element. The event type should be one of the configured `event_types`. Note that replayed retained messages will be discarded
+	// Default: <no value>
+	StateTopic string `json:"state_topic"`
+
+	// An ID that uniquely identifies this event device. If two events have the same unique ID, Home Assistant will raise an exception. Required when used with device-based discovery
+	// Default: <no value>
+	UniqueId string `json:"unique_id,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the value and render it to a valid JSON event payload. If the template throws an error, the current state will be used instead
+	// Default: <no value>
+	ValueTemplate string `json:"value_template,omitempty"`
+}
+
+// AnnounceTopic returns the topic to announce the discoverable Event
+// Topic has the format below:
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
+// 'object_id' is either the UniqueId, the Name, or a hash of the Event
+func (d *Event) AnnounceTopic(prefix string) string {
+	topicFormat := "%s/event/%s/config"
+	objectID := ""
+	switch {
+	case d.UniqueId != "":
+		objectID = d.UniqueId
+	case d.Name != "":
+		objectID = d.Name
+	default:
+		objectID = hash(d)
+	}
+
+	return fmt.Sprintf(topicFormat, prefix, objectID)
+}
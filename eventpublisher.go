@@ -0,0 +1,73 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EventPublisher publishes the events of an Event to Home Assistant.
+type EventPublisher struct {
+	Event     *Event
+	Publisher Publisher
+	// Triggers are fired along with the events of the matching type, for Home Assistant
+	// installs that predate the event platform. They are keyed by event type.
+	Triggers map[string]*DeviceTrigger
+}
+
+// NewEventPublisher creates an EventPublisher that publishes the events of e with p.
+func NewEventPublisher(e *Event, p Publisher) *EventPublisher {
+	return &EventPublisher{
+		Event:     e,
+		Publisher: p,
+	}
+}
+
+// Publish publishes an event of the given type, along with its attributes, as
+//
+//	{"event_type": eventType, <attrs>...}
+//
+// The event type must be one of the Event's EventTypes. If there is a DeviceTrigger for the
+// event type it is fired too, with its Payload, or with the event type if it has none.
+func (ep *EventPublisher) Publish(eventType string, attrs map[string]interface{}) error {
+	if !ep.declared(eventType) {
+		return fmt.Errorf("event type %q is not one of the event types %v", eventType, ep.Event.EventTypes)
+	}
+
+	payload := make(map[string]interface{}, len(attrs)+1)
+	for k, v := range attrs {
+		payload[k] = v
+	}
+	payload["event_type"] = eventType
+
+	bs, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %v", err)
+	}
+
+	err = ep.Publisher.Publish(ep.Event.StateTopic, byte(ep.Event.Qos), false, bs)
+	if err != nil {
+		return fmt.Errorf("could not publish event: %v", err)
+	}
+
+	if dt, ok := ep.Triggers[eventType]; ok {
+		p := dt.Payload
+		if p == "" {
+			p = eventType
+		}
+		err = ep.Publisher.Publish(dt.Topic, byte(dt.Qos), false, []byte(p))
+		if err != nil {
+			return fmt.Errorf("could not fire device trigger: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (ep *EventPublisher) declared(eventType string) bool {
+	for _, et := range ep.Event.EventTypes {
+		if et == eventType {
+			return true
+		}
+	}
+	return false
+}
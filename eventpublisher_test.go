@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEventPublisher(t *testing.T) {
+	p := &testPublisher{}
+	e := &Event{
+		StateTopic: "doorbell/event",
+		EventTypes: []string{"press", "hold"},
+	}
+	ep := NewEventPublisher(e, p)
+	ep.Triggers = map[string]*DeviceTrigger{
+		"hold": {Topic: "doorbell/trigger", Type: "button_long_press", Subtype: "button_1"},
+	}
+
+	if err := ep.Publish("ring", nil); err == nil {
+		t.Fatalf("expected an error publishing an undeclared event type")
+	}
+	if len(*p) != 0 {
+		t.Fatalf("published an undeclared event type")
+	}
+
+	if err := ep.Publish("press", map[string]interface{}{"button": 1, "event_type": "hold"}); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+	if err := ep.Publish("hold", nil); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+
+	if len(*p) != 3 {
+		t.Fatalf("got %d messages, want 3: %+v", len(*p), *p)
+	}
+
+	got := map[string]interface{}{}
+	if err := json.Unmarshal([]byte((*p)[0].payload), &got); err != nil {
+		t.Fatalf("event is not json: %v", err)
+	}
+	want := map[string]interface{}{"event_type": "press", "button": float64(1)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got event %v, want %v", got, want)
+	}
+
+	if tr := (*p)[2]; tr.topic != "doorbell/trigger" || tr.payload != "hold" {
+		t.Errorf("device trigger was not fired: %+v", tr)
+	}
+}
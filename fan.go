@@ -174,7 +174,7 @@ type Fan struct {
 
 	// List of preset modes this fan is capable of running at. Common examples include `auto`, `smart`, `whoosh`, `eco` and `breeze`
 	// Default: []
-	PresetModes string `json:"preset_modes,omitempty"`
+	PresetModes []string `json:"preset_modes,omitempty"`
 
 	// The maximum QoS level to be used when receiving and publishing messages
 	// Default: 0
@@ -207,7 +207,9 @@ type Fan struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Fan
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Fan
 func (d *Fan) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/fan/%s/config"
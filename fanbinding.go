@@ -0,0 +1,350 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Directions of a Fan.
+const (
+	FanDirectionForward = "forward"
+	FanDirectionReverse = "reverse"
+)
+
+// DefaultPayloadReset is the payload a Fan publishes to reset the percentage or preset mode,
+// if PayloadResetPercentage or PayloadResetPresetMode is not set.
+const DefaultPayloadReset = "None"
+
+// speedStates returns the number of speeds from min to max.
+func speedStates(min, max int) int {
+	return max - min + 1
+}
+
+// PercentageToSpeed converts a percentage to a speed from min to max, rounding up as Home
+// Assistant does when it sends a percentage command. A percentage of 0 is one below min.
+func PercentageToSpeed(percentage, min, max int) int {
+	offset := min - 1
+	return int(math.Ceil(float64(speedStates(min, max)*percentage)/100)) + offset
+}
+
+// SpeedToPercentage converts a speed from min to max to a percentage, rounding down as Home
+// Assistant does when it receives a percentage state.
+func SpeedToPercentage(speed, min, max int) int {
+	offset := min - 1
+	return (speed - offset) * 100 / speedStates(min, max)
+}
+
+// PercentageToOrderedListItem returns the item of the ordered list of speeds for a percentage,
+// as Home Assistant does. The list must not be empty.
+func PercentageToOrderedListItem(list []string, percentage int) string {
+	for i, item := range list {
+		if percentage <= (i+1)*100/len(list) {
+			return item
+		}
+	}
+	return list[len(list)-1]
+}
+
+// OrderedListItemToPercentage returns the percentage of an item of the ordered list of speeds,
+// as Home Assistant does.
+func OrderedListItemToPercentage(list []string, item string) (int, error) {
+	for i, v := range list {
+		if v == item {
+			return (i + 1) * 100 / len(list), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not in the list", item)
+}
+
+// SpeedRange returns the speed range of the Fan, with the defaults for any that are not set.
+func (f *Fan) SpeedRange() (min, max int) {
+	min, max = f.SpeedRangeMin, f.SpeedRangeMax
+	if min == 0 {
+		min = 1
+	}
+	if max == 0 {
+		max = 100
+	}
+	return min, max
+}
+
+// FanCommandKind is the kind of a command sent to a fan.
+type FanCommandKind int
+
+const (
+	FanCommandState FanCommandKind = iota
+	FanCommandPercentage
+	FanCommandPresetMode
+	FanCommandOscillation
+	FanCommandDirection
+)
+
+// FanCommand is a command sent to a fan. Only the fields of its Kind are set.
+type FanCommand struct {
+	Kind FanCommandKind
+	On   bool
+	// Percentage is the speed as a percentage, and Speed the speed in the Fan's speed range.
+	Percentage  int
+	Speed       int
+	PresetMode  string
+	Oscillating bool
+	Direction   string
+}
+
+// FanState is the state of a fan.
+type FanState struct {
+	On bool
+	// Percentage is nil if it is not known, for example in a preset mode.
+	Percentage *int
+	// PresetMode is empty if the fan is not in a preset mode.
+	PresetMode  string
+	Oscillating bool
+	Direction   string
+}
+
+// FanBinding binds a fan to the command and state topics of a Fan. Commands from Home
+// Assistant are decoded and passed to OnCommand. When it succeeds the state is updated and
+// published. State changes on the device are published with the Set methods.
+//
+// Setting a preset mode resets the percentage, and setting a percentage resets the preset
+// mode, publishing the reset payloads.
+type FanBinding struct {
+	Fan       *Fan
+	Publisher Publisher
+
+	OnCommand func(FanCommand) error
+	// OnError is called with the errors from handling commands.
+	OnError func(error)
+
+	mu    sync.Mutex
+	state FanState
+}
+
+// NewFanBinding creates a FanBinding for f that publishes with p.
+func NewFanBinding(f *Fan, p Publisher) *FanBinding {
+	return &FanBinding{
+		Fan:       f,
+		Publisher: p,
+		state:     FanState{Direction: FanDirectionForward},
+	}
+}
+
+// State returns the current state.
+func (b *FanBinding) State() FanState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *FanBinding) publish(topic, payload string) error {
+	if topic == "" {
+		return nil
+	}
+	err := b.Publisher.Publish(topic, byte(b.Fan.Qos), b.Fan.Retain, []byte(payload))
+	if err != nil {
+		return fmt.Errorf("could not publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+func (b *FanBinding) onOff(on bool) string {
+	if on {
+		return orDefault(b.Fan.PayloadOn, "ON")
+	}
+	return orDefault(b.Fan.PayloadOff, "OFF")
+}
+
+func (b *FanBinding) oscillation(on bool) string {
+	if on {
+		return orDefault(b.Fan.PayloadOscillationOn, "oscillate_on")
+	}
+	return orDefault(b.Fan.PayloadOscillationOff, "oscillate_off")
+}
+
+// SetOn sets whether the fan is on, and publishes it to the StateTopic.
+func (b *FanBinding) SetOn(on bool) error {
+	b.mu.Lock()
+	b.state.On = on
+	b.mu.Unlock()
+
+	return b.publish(b.Fan.StateTopic, b.onOff(on))
+}
+
+// SetPercentage sets the speed as a percentage, and publishes it to the PercentageStateTopic
+// as a speed in the speed range. It resets the preset mode.
+func (b *FanBinding) SetPercentage(percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("percentage %d is outside 0 to 100", percentage)
+	}
+
+	b.mu.Lock()
+	b.state.Percentage = &percentage
+	reset := b.state.PresetMode != ""
+	b.state.PresetMode = ""
+	b.mu.Unlock()
+
+	min, max := b.Fan.SpeedRange()
+	err := b.publish(b.Fan.PercentageStateTopic, strconv.Itoa(PercentageToSpeed(percentage, min, max)))
+	if err != nil || !reset {
+		return err
+	}
+	return b.publish(b.Fan.PresetModeStateTopic, orDefault(b.Fan.PayloadResetPresetMode, DefaultPayloadReset))
+}
+
+// SetPresetMode sets the preset mode, and publishes it to the PresetModeStateTopic. It resets
+// the percentage. An empty mode resets the preset mode.
+func (b *FanBinding) SetPresetMode(mode string) error {
+	if mode != "" && !contains(b.Fan.PresetModes, mode) {
+		return fmt.Errorf("preset mode %q is not supported", mode)
+	}
+
+	b.mu.Lock()
+	b.state.PresetMode = mode
+	reset := mode != "" && b.state.Percentage != nil
+	if reset {
+		b.state.Percentage = nil
+	}
+	b.mu.Unlock()
+
+	err := b.publish(b.Fan.PresetModeStateTopic, orDefault(mode, orDefault(b.Fan.PayloadResetPresetMode, DefaultPayloadReset)))
+	if err != nil || !reset {
+		return err
+	}
+	return b.publish(b.Fan.PercentageStateTopic, orDefault(b.Fan.PayloadResetPercentage, DefaultPayloadReset))
+}
+
+// SetOscillating sets whether the fan oscillates, and publishes it to the
+// OscillationStateTopic.
+func (b *FanBinding) SetOscillating(on bool) error {
+	b.mu.Lock()
+	b.state.Oscillating = on
+	b.mu.Unlock()
+
+	return b.publish(b.Fan.OscillationStateTopic, b.oscillation(on))
+}
+
+// SetDirection sets the direction, and publishes it to the DirectionStateTopic.
+func (b *FanBinding) SetDirection(direction string) error {
+	if direction != FanDirectionForward && direction != FanDirectionReverse {
+		return fmt.Errorf("unknown direction %q", direction)
+	}
+
+	b.mu.Lock()
+	b.state.Direction = direction
+	b.mu.Unlock()
+
+	return b.publish(b.Fan.DirectionStateTopic, direction)
+}
+
+// Subscribe subscribes the binding to the Fan's command topics.
+func (b *FanBinding) Subscribe(s Subscriber) error {
+	for _, sub := range []struct {
+		topic string
+		kind  FanCommandKind
+	}{
+		{b.Fan.CommandTopic, FanCommandState},
+		{b.Fan.PercentageCommandTopic, FanCommandPercentage},
+		{b.Fan.PresetModeCommandTopic, FanCommandPresetMode},
+		{b.Fan.OscillationCommandTopic, FanCommandOscillation},
+		{b.Fan.DirectionCommandTopic, FanCommandDirection},
+	} {
+		if sub.topic == "" {
+			continue
+		}
+		kind := sub.kind
+		err := s.Subscribe(sub.topic, byte(b.Fan.Qos), func(m Message) {
+			if err := b.HandleCommand(kind, m); err != nil && b.OnError != nil {
+				b.OnError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", sub.topic, err)
+		}
+	}
+	return nil
+}
+
+// Decode decodes a message on the command topic of the given kind.
+func (b *FanBinding) Decode(kind FanCommandKind, payload []byte) (FanCommand, error) {
+	c := FanCommand{Kind: kind}
+	p := strings.TrimSpace(string(payload))
+
+	switch kind {
+	case FanCommandState:
+		switch p {
+		case b.onOff(true):
+			c.On = true
+		case b.onOff(false):
+		default:
+			return c, fmt.Errorf("unknown fan state %q", p)
+		}
+
+	case FanCommandPercentage:
+		speed, err := strconv.Atoi(p)
+		if err != nil {
+			return c, fmt.Errorf("could not parse speed: %v", err)
+		}
+		min, max := b.Fan.SpeedRange()
+		// the speed below the range is off
+		if speed < min-1 || speed > max {
+			return c, fmt.Errorf("speed %d is outside %d to %d", speed, min, max)
+		}
+		c.Speed = speed
+		c.Percentage = SpeedToPercentage(speed, min, max)
+
+	case FanCommandPresetMode:
+		if !contains(b.Fan.PresetModes, p) {
+			return c, fmt.Errorf("preset mode %q is not supported", p)
+		}
+		c.PresetMode = p
+
+	case FanCommandOscillation:
+		switch p {
+		case b.oscillation(true):
+			c.Oscillating = true
+		case b.oscillation(false):
+		default:
+			return c, fmt.Errorf("unknown oscillation %q", p)
+		}
+
+	case FanCommandDirection:
+		if p != FanDirectionForward && p != FanDirectionReverse {
+			return c, fmt.Errorf("unknown direction %q", p)
+		}
+		c.Direction = p
+
+	default:
+		return c, fmt.Errorf("unknown fan command kind %d", kind)
+	}
+
+	return c, nil
+}
+
+// HandleCommand decodes a message on the command topic of the given kind, passes it to
+// OnCommand, and updates the state.
+func (b *FanBinding) HandleCommand(kind FanCommandKind, m Message) error {
+	c, err := b.Decode(kind, m.Payload)
+	if err != nil {
+		return err
+	}
+	if b.OnCommand != nil {
+		if err := b.OnCommand(c); err != nil {
+			return err
+		}
+	}
+
+	switch c.Kind {
+	case FanCommandState:
+		return b.SetOn(c.On)
+	case FanCommandPercentage:
+		return b.SetPercentage(c.Percentage)
+	case FanCommandPresetMode:
+		return b.SetPresetMode(c.PresetMode)
+	case FanCommandOscillation:
+		return b.SetOscillating(c.Oscillating)
+	}
+	return b.SetDirection(c.Direction)
+}
@@ -0,0 +1,152 @@
+package discovery
+
+import "testing"
+
+func TestFanSpeedConversion(t *testing.T) {
+	for _, tc := range []struct{ percentage, min, max, speed int }{
+		{100, 1, 255, 255},
+		{50, 1, 255, 128},
+		{0, 1, 255, 0},
+		{33, 1, 3, 1},
+		{66, 1, 3, 2},
+		{100, 1, 3, 3},
+		{1, 1, 3, 1},
+		{50, 10, 19, 14},
+	} {
+		if got := PercentageToSpeed(tc.percentage, tc.min, tc.max); got != tc.speed {
+			t.Errorf("PercentageToSpeed(%d, %d, %d) = %d, want %d", tc.percentage, tc.min, tc.max, got, tc.speed)
+		}
+	}
+
+	for _, tc := range []struct{ speed, min, max, percentage int }{
+		{255, 1, 255, 100},
+		{127, 1, 255, 49},
+		{10, 1, 255, 3},
+		{1, 1, 3, 33},
+		{2, 1, 3, 66},
+		{3, 1, 3, 100},
+		{14, 10, 19, 50},
+	} {
+		if got := SpeedToPercentage(tc.speed, tc.min, tc.max); got != tc.percentage {
+			t.Errorf("SpeedToPercentage(%d, %d, %d) = %d, want %d", tc.speed, tc.min, tc.max, got, tc.percentage)
+		}
+	}
+
+	speeds := []string{"low", "medium", "high"}
+	for _, tc := range []struct {
+		percentage int
+		item       string
+	}{
+		{1, "low"},
+		{33, "low"},
+		{34, "medium"},
+		{66, "medium"},
+		{67, "high"},
+		{100, "high"},
+	} {
+		if got := PercentageToOrderedListItem(speeds, tc.percentage); got != tc.item {
+			t.Errorf("PercentageToOrderedListItem(%d) = %s, want %s", tc.percentage, got, tc.item)
+		}
+	}
+	if p, err := OrderedListItemToPercentage(speeds, "medium"); err != nil || p != 66 {
+		t.Errorf("OrderedListItemToPercentage(medium) = %d, %v", p, err)
+	}
+	if _, err := OrderedListItemToPercentage(speeds, "turbo"); err == nil {
+		t.Errorf("expected an error for an unknown speed")
+	}
+}
+
+func TestFanBinding(t *testing.T) {
+	s := testSubscriber{}
+	p := &testPublisher{}
+	f := &Fan{
+		CommandTopic:            "fan/set",
+		StateTopic:              "fan/state",
+		PercentageCommandTopic:  "fan/speed/set",
+		PercentageStateTopic:    "fan/speed",
+		PresetModeCommandTopic:  "fan/preset/set",
+		PresetModeStateTopic:    "fan/preset",
+		PresetModes:             []string{"breeze", "sleep"},
+		OscillationCommandTopic: "fan/oscillation/set",
+		OscillationStateTopic:   "fan/oscillation",
+		DirectionCommandTopic:   "fan/direction/set",
+		DirectionStateTopic:     "fan/direction",
+		SpeedRangeMin:           1,
+		SpeedRangeMax:           3,
+		PayloadResetPresetMode:  "none",
+	}
+	b := NewFanBinding(f, p)
+	var commands []FanCommand
+	b.OnCommand = func(c FanCommand) error {
+		commands = append(commands, c)
+		return nil
+	}
+	var errs []error
+	b.OnError = func(err error) { errs = append(errs, err) }
+	if err := b.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	s["fan/set"](Message{Payload: []byte("ON")})
+	s["fan/speed/set"](Message{Payload: []byte("2")})
+	s["fan/preset/set"](Message{Payload: []byte("breeze")})
+	s["fan/speed/set"](Message{Payload: []byte("3")})
+	s["fan/oscillation/set"](Message{Payload: []byte("oscillate_on")})
+	s["fan/direction/set"](Message{Payload: []byte("reverse")})
+
+	s["fan/speed/set"](Message{Payload: []byte("7")})
+	s["fan/preset/set"](Message{Payload: []byte("storm")})
+	s["fan/direction/set"](Message{Payload: []byte("sideways")})
+	if len(errs) != 3 {
+		t.Errorf("got errors %v, want 3", errs)
+	}
+
+	want := []struct{ topic, payload string }{
+		{"fan/state", "ON"},
+		{"fan/speed", "2"},
+		{"fan/preset", "breeze"},
+		{"fan/speed", "None"},
+		{"fan/speed", "3"},
+		{"fan/preset", "none"},
+		{"fan/oscillation", "oscillate_on"},
+		{"fan/direction", "reverse"},
+	}
+	if len(*p) != len(want) {
+		t.Fatalf("published %+v, want %v", *p, want)
+	}
+	for i, w := range want {
+		if m := (*p)[i]; m.topic != w.topic || m.payload != w.payload {
+			t.Errorf("published %s %s, want %s %s", m.topic, m.payload, w.topic, w.payload)
+		}
+	}
+
+	if c := commands[1]; c.Kind != FanCommandPercentage || c.Speed != 2 || c.Percentage != 66 {
+		t.Errorf("got command %+v", c)
+	}
+	st := b.State()
+	if !st.On || st.Percentage == nil || *st.Percentage != 100 || st.PresetMode != "" || !st.Oscillating || st.Direction != "reverse" {
+		t.Errorf("state is %+v", st)
+	}
+}
+
+func TestFanBindingSpeedRange(t *testing.T) {
+	b := NewFanBinding(&Fan{SpeedRangeMin: 1, SpeedRangeMax: 1000}, &testPublisher{})
+	for _, tc := range []struct {
+		speed      string
+		percentage int
+		ok         bool
+	}{
+		{"0", 0, true},
+		{"1000", 100, true},
+		{"1001", 0, false},
+		{"-1", 0, false},
+	} {
+		c, err := b.Decode(FanCommandPercentage, []byte(tc.speed))
+		if (err == nil) != tc.ok {
+			t.Errorf("speed %s: got error %v, want ok %v", tc.speed, err, tc.ok)
+		}
+		if err == nil && c.Percentage != tc.percentage {
+			t.Errorf("speed %s: got %d%%, want %d%%", tc.speed, c.Percentage, tc.percentage)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"sync"
+	"time"
+)
+
+// FramePublisher publishes the frames of a Camera to its Topic, base64 encoded if its
+// ImageEncoding is b64.
+//
+// Frames are dropped, rather than queued, if they come faster than MaxFrameRate, or while the
+// previous frame is still being published, so that a slow broker doesn't hold up the camera.
+type FramePublisher struct {
+	Camera    *Camera
+	Publisher Publisher
+	Clock     Clock
+	// MaxFrameRate is the most frames published per second. There is no limit if it is 0.
+	MaxFrameRate float64
+	// MaxPayloadSize is the largest payload published, after encoding, in bytes. There is no
+	// limit if it is 0.
+	MaxPayloadSize int
+	// JPEGOptions are used to encode frames. The default quality is used if it is nil.
+	JPEGOptions *jpeg.Options
+
+	mu      sync.Mutex
+	busy    bool
+	last    time.Time
+	dropped int
+}
+
+// NewFramePublisher creates a FramePublisher that publishes the frames of c with p.
+func NewFramePublisher(c *Camera, p Publisher) *FramePublisher {
+	return &FramePublisher{
+		Camera:    c,
+		Publisher: p,
+		Clock:     RealClock,
+	}
+}
+
+// PublishImage encodes img as a JPEG and publishes it, unless the frame is dropped.
+func (fp *FramePublisher) PublishImage(img image.Image) error {
+	if !fp.start() {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, fp.JPEGOptions); err != nil {
+		fp.finish(false)
+		return fmt.Errorf("could not encode frame: %v", err)
+	}
+	return fp.publish(buf.Bytes())
+}
+
+// PublishFrame publishes an already encoded frame as it is, unless the frame is dropped.
+func (fp *FramePublisher) PublishFrame(frame []byte) error {
+	if !fp.start() {
+		return nil
+	}
+	return fp.publish(frame)
+}
+
+// Dropped returns the number of frames dropped so far.
+func (fp *FramePublisher) Dropped() int {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	return fp.dropped
+}
+
+// start reserves the publisher for a frame, or counts the frame as dropped if it is busy or
+// the last frame was too recent.
+func (fp *FramePublisher) start() bool {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.busy || fp.tooSoon() {
+		fp.dropped++
+		return false
+	}
+	fp.busy = true
+	return true
+}
+
+func (fp *FramePublisher) tooSoon() bool {
+	if fp.MaxFrameRate <= 0 || fp.last.IsZero() {
+		return false
+	}
+	interval := time.Duration(float64(time.Second) / fp.MaxFrameRate)
+	return fp.Clock.Now().Sub(fp.last) < interval
+}
+
+// finish releases the publisher, and starts the frame interval again if a frame was
+// published.
+func (fp *FramePublisher) finish(published bool) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	fp.busy = false
+	if published {
+		fp.last = fp.Clock.Now()
+	}
+}
+
+func (fp *FramePublisher) publish(frame []byte) error {
+	if fp.Camera.ImageEncoding == "b64" {
+		frame = encodeBase64(frame)
+	}
+	if fp.MaxPayloadSize > 0 && len(frame) > fp.MaxPayloadSize {
+		fp.finish(false)
+		return fmt.Errorf("frame is %d bytes, more than the limit of %d", len(frame), fp.MaxPayloadSize)
+	}
+
+	err := fp.Publisher.Publish(fp.Camera.Topic, 0, false, frame)
+	fp.finish(err == nil)
+	if err != nil {
+		return fmt.Errorf("could not publish frame: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+// blockingPublisher publishes a frame from inside Publish, as another goroutine would while
+// the broker is slow.
+type blockingPublisher struct {
+	testPublisher
+	fp     *FramePublisher
+	nested error
+}
+
+func (p *blockingPublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	if p.fp != nil {
+		fp := p.fp
+		p.fp = nil
+		p.nested = fp.PublishFrame([]byte("nested"))
+	}
+	return p.testPublisher.Publish(topic, qos, retained, payload)
+}
+
+func TestFramePublisher(t *testing.T) {
+	p := &testPublisher{}
+	clock := newTestClock()
+	c := &Camera{Topic: "camera/frame", ImageEncoding: "b64"}
+	fp := NewFramePublisher(c, p)
+	fp.Clock = clock
+	fp.MaxFrameRate = 2
+
+	if err := fp.PublishImage(image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("could not publish image: %v", err)
+	}
+	if len(*p) != 1 {
+		t.Fatalf("got %d messages, want 1", len(*p))
+	}
+	bs, err := base64.StdEncoding.DecodeString((*p)[0].payload)
+	if err != nil {
+		t.Fatalf("frame is not base64: %v", err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(bs)); err != nil {
+		t.Errorf("frame is not a jpeg: %v", err)
+	}
+
+	clock.Advance(300 * time.Millisecond)
+	fp.PublishFrame([]byte("too soon"))
+	clock.Advance(200 * time.Millisecond)
+	fp.PublishFrame([]byte("frame"))
+	if len(*p) != 2 || (*p)[1].payload != base64.StdEncoding.EncodeToString([]byte("frame")) {
+		t.Errorf("published %+v", *p)
+	}
+	if fp.Dropped() != 1 {
+		t.Errorf("dropped %d frames, want 1", fp.Dropped())
+	}
+
+	clock.Advance(time.Second)
+	fp.MaxPayloadSize = 8
+	if err := fp.PublishFrame([]byte("a large frame")); err == nil {
+		t.Errorf("expected an error publishing a frame over the size limit")
+	}
+	if err := fp.PublishFrame([]byte("small")); err != nil {
+		t.Errorf("could not publish after an oversized frame: %v", err)
+	}
+	if len(*p) != 3 {
+		t.Errorf("got %d messages, want 3", len(*p))
+	}
+}
+
+func TestFramePublisherBackpressure(t *testing.T) {
+	p := &blockingPublisher{}
+	fp := NewFramePublisher(&Camera{Topic: "camera/frame"}, p)
+	p.fp = fp
+
+	if err := fp.PublishFrame([]byte("first")); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+	if p.nested != nil {
+		t.Errorf("dropping a frame returned %v", p.nested)
+	}
+	if len(p.testPublisher) != 1 || p.testPublisher[0].payload != "first" {
+		t.Errorf("published %+v", p.testPublisher)
+	}
+	if fp.Dropped() != 1 {
+		t.Errorf("dropped %d frames, want 1", fp.Dropped())
+	}
+
+	if err := fp.PublishFrame([]byte("second")); err != nil || len(p.testPublisher) != 2 {
+		t.Errorf("could not publish once the publisher was free: %v", err)
+	}
+}
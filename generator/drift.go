@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// changeKind is the kind of difference between the upstream configuration and a generated
+// struct.
+type changeKind string
+
+const (
+	changeAdded    changeKind = "added"
+	changeRemoved  changeKind = "removed"
+	changeType     changeKind = "type"
+	changeDefault  changeKind = "default"
+	changeRequired changeKind = "required"
+)
+
+// change is a single difference found by drift.
+type change struct {
+	Type string     `json:"type"`
+	Key  string     `json:"key"`
+	Kind changeKind `json:"kind"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// Breaking reports whether the change breaks existing users of the struct or existing
+// discovery payloads.
+func (c change) Breaking() bool {
+	switch c.Kind {
+	case changeRemoved, changeType:
+		return true
+	case changeRequired:
+		return c.New == "true"
+	}
+	return false
+}
+
+// structField describes a configuration key as it appears in a generated struct.
+type structField struct {
+	Type     string
+	Default  string
+	Required bool
+}
+
+// defaultString formats a default the same way the discoverable template does.
+func defaultString(v interface{}) string {
+	if v == nil {
+		return "<no value>"
+	}
+	return fmt.Sprint(v)
+}
+
+// readStruct reads the fields of the struct named typ from a generated go file, keyed by
+// their configuration key.
+func readStruct(fn, typ string) (map[string]structField, error) {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, fn, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", fn, err)
+	}
+
+	var st *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != typ {
+			return st == nil
+		}
+		st, _ = ts.Type.(*ast.StructType)
+		return false
+	})
+	if st == nil {
+		return nil, fmt.Errorf("%s does not declare struct %s", fn, typ)
+	}
+
+	fields := make(map[string]structField)
+	for _, fld := range st.Fields.List {
+		if fld.Tag == nil {
+			continue
+		}
+		tag := reflect.StructTag(strings.Trim(fld.Tag.Value, "`")).Get("json")
+		opts := strings.Split(tag, ",")
+
+		sf := structField{
+			Type:     exprString(fs, fld.Type),
+			Required: len(opts) == 1,
+		}
+		if fld.Doc != nil {
+			for _, c := range fld.Doc.List {
+				if d := strings.TrimPrefix(c.Text, "// Default: "); d != c.Text {
+					sf.Default = d
+				}
+			}
+		}
+		fields[opts[0]] = sf
+	}
+
+	return fields, nil
+}
+
+func exprString(fs *token.FileSet, e ast.Expr) string {
+	sb := &strings.Builder{}
+	err := printer.Fprint(sb, fs, e)
+	if err != nil {
+		return fmt.Sprintf("%T", e)
+	}
+	return sb.String()
+}
+
+// compare returns the changes needed to get from the fields of a generated struct to the
+// configuration entries.
+func compare(typ string, old map[string]structField, entries map[string]*entry) []change {
+	changes := []change{}
+	for k, e := range entries {
+		ty := e.GoType
+		def := defaultString(e.Default)
+		f, ok := old[k]
+		switch {
+		case !ok:
+			changes = append(changes, change{Type: typ, Key: k, Kind: changeAdded, New: ty})
+			continue
+		case f.Type != ty:
+			changes = append(changes, change{Type: typ, Key: k, Kind: changeType, Old: f.Type, New: ty})
+		}
+		if f.Default != def {
+			changes = append(changes, change{Type: typ, Key: k, Kind: changeDefault, Old: f.Default, New: def})
+		}
+		if f.Required != e.Required {
+			changes = append(changes, change{Type: typ, Key: k, Kind: changeRequired, Old: fmt.Sprint(f.Required), New: fmt.Sprint(e.Required)})
+		}
+	}
+	for k, f := range old {
+		if _, ok := entries[k]; !ok {
+			changes = append(changes, change{Type: typ, Key: k, Kind: changeRemoved, Old: f.Type})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// drift compares the configuration of each platform in the manifest with the struct
+// generated in dir.
+func drift(m *manifest, s source, dir string) ([]change, error) {
+	changes := []change{}
+	for _, p := range m.Platforms {
+		bs, err := s.ReadFile(p.Component)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", p.Component, err)
+		}
+		entries, err := platformEntries(p, bs)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", p.Component, err)
+		}
+
+		old := map[string]structField{}
+		fn := filepath.Join(dir, p.fileName())
+		if _, err := os.Stat(fn); err == nil {
+			old, err = readStruct(fn, p.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, compare(p.Type, old, entries)...)
+	}
+	return changes, nil
+}
+
+// writeReport writes the changes as a table, or as json.
+func writeReport(w io.Writer, changes []change, asJSON bool) error {
+	if asJSON {
+		type report struct {
+			change
+			Breaking bool `json:"breaking"`
+		}
+		rs := []report{}
+		for _, c := range changes {
+			rs = append(rs, report{change: c, Breaking: c.Breaking()})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rs)
+	}
+
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tKEY\tCHANGE\tOLD\tNEW\tBREAKING")
+	for _, c := range changes {
+		breaking := ""
+		if c.Breaking() {
+			breaking = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Type, c.Key, c.Kind, c.Old, c.New, breaking)
+	}
+	return tw.Flush()
+}
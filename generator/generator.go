@@ -1,23 +1,24 @@
-//go:build ignore
-
 package main
 
 import (
 	"bytes"
+	"embed"
 	"fmt"
 	"go/format"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"os"
 	"strings"
 	"text/template"
 
 	"gopkg.in/yaml.v3"
 )
 
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
 type entry struct {
 	name        string
+	GoName      string                  `yaml:"-"`
+	GoType      string                  `yaml:"-"`
 	Default     interface{}             `yaml:"default,omitempty"`
 	Description string                  `yaml:"description"`
 	Required    bool                    `yaml:"required"`
@@ -31,18 +32,6 @@ const (
 	cfe = "{% endconfiguration %}"
 )
 
-const discTemplate = `package discovery
-
-type {{.Name | convertKey}} struct {
-	{{range $key, $value := .Data}}
-	{{$value.Description | comment}}
-	// Default: {{$value.Default}}
-	{{$key | convertKey}} {{$value | getType}} ` + "`json:\"{{$key}}{{if not $value.Required}},omitempty{{end}}\"`" + `
-	{{end}}
-}
-
-`
-
 func inList(s string, l []interface{}) bool {
 	for _, li := range l {
 		if v, ok := li.(string); ok && s == v {
@@ -105,80 +94,109 @@ func getType(n string, ty interface{}) string {
 	return "string"
 }
 
-func getTypeFromEntry(e entry) string {
-	return getType(e.name, e.Type)
-}
-
-func getBytes(src string) ([]byte, error) {
-	prefix := strings.SplitN(src, ":", 2)[0]
-
-	if prefix == "http" || prefix == "https" {
-		resp, err := http.Get(src)
-		if err != nil {
-			return nil, fmt.Errorf("could not get url: %v", err)
-		}
-
-		bs, err := ioutil.ReadAll(resp.Body)
-		defer resp.Body.Close()
-		if err != nil {
-			return nil, fmt.Errorf("could not read body: %v", err)
-		}
-
-		return bs, nil
-	}
-
-	return ioutil.ReadFile(src)
-}
-
 type templateData struct {
 	Name    string
 	RawName string
 	Data    map[string]*entry
 }
 
-func readYAML(url string) error {
-	bs, err := getBytes(url)
+// loadTemplates parses the embedded templates used to render the discovery structs.
+func loadTemplates() (*template.Template, error) {
+	funcMap := template.FuncMap{
+		"convertKey":          convertKey,
+		"comment":             comment,
+		"enumConst":           enumConst,
+		"sortedAbbreviations": sortedAbbreviations,
+	}
+
+	t, err := template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/*.tmpl")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("could not create template: %v", err)
 	}
+	return t, nil
+}
 
+// parseConfiguration extracts the entries of the nth configuration section from an
+// integration's markdown.
+func parseConfiguration(bs []byte, section int) (map[string]*entry, error) {
 	// find the begining and end of the configuration sections
-	m := make(map[string]*entry)
+	for i := 0; i < section; i++ {
+		end := bytes.Index(bs, []byte(cfe))
+		if end < 0 {
+			return nil, fmt.Errorf("could not find configuration section %d", section)
+		}
+		bs = bs[end+len(cfe):]
+	}
 
-	start := bytes.Index(bs, []byte(cfs)) + len(cfs)
+	start := bytes.Index(bs, []byte(cfs))
 	end := bytes.Index(bs, []byte(cfe))
-	err = yaml.Unmarshal(bs[start:end], m)
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("could not find configuration section %d", section)
+	}
+	start += len(cfs)
+
+	m := make(map[string]*entry)
+	err := yaml.Unmarshal(bs[start:end], m)
 	if err != nil {
-		fmt.Printf("%s\n", bs[start:end])
-		return fmt.Errorf("could not unmarshal bytes: %v", err)
+		return nil, fmt.Errorf("could not unmarshal bytes: %v", err)
 	}
 
 	for k := range m {
 		m[k].name = k
 	}
 
-	s := templateData{Data: m}
+	return m, nil
+}
 
-	su := strings.Split(url, "/")
-	fn := su[len(su)-1]
-	s.RawName = strings.TrimSuffix(fn, ".mqtt.markdown")
-	s.Name = convertKey(s.RawName)
+// platformEntries returns the configuration entries of the platform, with the go name and type
+// of each entry set.
+func platformEntries(p platform, bs []byte) (map[string]*entry, error) {
+	m, err := parseConfiguration(bs, p.Section)
+	if err != nil {
+		return nil, err
+	}
 
-	funcMap := template.FuncMap{
-		"convertKey": convertKey,
-		"getType":    getTypeFromEntry,
-		"comment":    comment,
+	for k, e := range m {
+		e.GoName = convertKey(k)
+		e.GoType = getType(k, e.Type)
+	}
+	for k, f := range p.Fields {
+		e, ok := m[k]
+		if !ok {
+			return nil, fmt.Errorf("override for unknown key %s", k)
+		}
+		if f.Name != "" {
+			e.GoName = f.Name
+		}
+		if f.Type != "" {
+			e.GoType = f.Type
+		}
 	}
 
-	t, err := template.New("").Funcs(funcMap).ParseGlob("generator/templates/*.tmpl")
+	return m, nil
+}
+
+// generate renders the go source for the platform from the integration's markdown.
+func generate(t *template.Template, p platform, bs []byte) ([]byte, error) {
+	m, err := platformEntries(p, bs)
 	if err != nil {
-		return fmt.Errorf("could not create template: %v", err)
+		return nil, err
+	}
+	return render(t, p, m)
+}
+
+// render renders the go source for the platform from its configuration entries.
+func render(t *template.Template, p platform, m map[string]*entry) ([]byte, error) {
+	s := templateData{
+		Data:    m,
+		RawName: p.Component,
+		Name:    p.Type,
 	}
 
 	dbs := &bytes.Buffer{}
-	err = t.ExecuteTemplate(dbs, "discoverable.tmpl", s)
+	err := t.ExecuteTemplate(dbs, "discoverable.tmpl", s)
 	if err != nil {
-		return fmt.Errorf("could not execute template: %v", err)
+		return nil, fmt.Errorf("could not execute template: %v", err)
 	}
 
 	output := dbs.Bytes()
@@ -191,33 +209,74 @@ func readYAML(url string) error {
 	tbs := &bytes.Buffer{}
 	err = t.ExecuteTemplate(tbs, tid, s)
 	if err != nil {
-		return fmt.Errorf("could not execute template: %v", err)
+		return nil, fmt.Errorf("could not execute template: %v", err)
 	}
 	output = append(output, tbs.Bytes()...)
 
-	fbs, err := format.Source(output)
-	if err != nil {
-		fmt.Printf("%s\n", output)
-		return fmt.Errorf("could not format output: %v", err)
-	}
+	return formatSource(output)
+}
 
-	filename := strings.ToLower(s.Name) + ".go"
-	err = ioutil.WriteFile(filename, fbs, 0664)
+func formatSource(src []byte) ([]byte, error) {
+	fbs, err := format.Source(src)
 	if err != nil {
-		return fmt.Errorf("could not write file: %v", err)
+		return nil, fmt.Errorf("could not format output: %v\n%s", err, src)
 	}
+	return fbs, nil
+}
 
-	return nil
+// shared lists the templates for the files generated once from the whole manifest.
+var shared = map[string]string{
+	"components.go":    "components.tmpl",
+	"abbreviations.go": "abbreviations.tmpl",
+	"enums.go":         "enums.tmpl",
 }
 
-func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <markdown_url>\n", os.Args[0])
-		os.Exit(1)
+// run generates every file in the manifest, returning the generated source by file name.
+func run(t *template.Template, m *manifest, s source) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	entries := make(map[string]map[string]*entry)
+
+	for _, p := range m.Platforms {
+		bs, err := s.ReadFile(p.Component)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", p.Component, err)
+		}
+
+		es, err := platformEntries(p, bs)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", p.Component, err)
+		}
+		entries[p.Type] = es
+
+		fbs, err := render(t, p, es)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate %s: %v", p.Type, err)
+		}
+		files[p.fileName()] = fbs
+	}
+
+	sd := sharedData{manifest: m, entries: entries}
+	for fn, tid := range shared {
+		bs := &bytes.Buffer{}
+		err := t.ExecuteTemplate(bs, tid, sd)
+		if err != nil {
+			return nil, fmt.Errorf("could not execute template: %v", err)
+		}
+
+		fbs, err := formatSource(bs.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("could not generate %s: %v", fn, err)
+		}
+		files[fn] = fbs
 	}
 
-	err := readYAML(os.Args[1])
+	ss, err := schemas(m, entries)
 	if err != nil {
-		log.Fatalf("could not read yaml: %v", err)
+		return nil, err
 	}
+	for fn, bs := range ss {
+		files[fn] = bs
+	}
+
+	return files, nil
 }
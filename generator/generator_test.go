@@ -0,0 +1,336 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	snapshot     = "testdata/_integrations"
+	lockPath     = "upstream.lock"
+	manifestPath = "manifest.yaml"
+)
+
+func snapshotPlatforms(t *testing.T) []string {
+	fns, err := filepath.Glob(filepath.Join(snapshot, "*"+suffix))
+	if err != nil {
+		t.Fatalf("could not list snapshot: %v", err)
+	}
+	if len(fns) == 0 {
+		t.Fatalf("snapshot is empty")
+	}
+
+	platforms := []string{}
+	for _, fn := range fns {
+		platforms = append(platforms, strings.TrimSuffix(filepath.Base(fn), suffix))
+	}
+	return platforms
+}
+
+// TestGolden checks that the committed files match the output generated from the manifest
+// and the locked snapshot.
+func TestGolden(t *testing.T) {
+	tmpl, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := readManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := readLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := run(tmpl, m, lockedSource{source: dirSource(snapshot), lock: l})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for fn, got := range files {
+		want, err := ioutil.ReadFile(filepath.Join("..", filepath.FromSlash(fn)))
+		if err != nil {
+			t.Fatalf("could not read golden file: %v", err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run go generate", fn)
+		}
+	}
+
+	committed, err := generatedFiles("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range committed {
+		if _, ok := files[fn]; !ok {
+			t.Errorf("%s is not generated from the manifest, remove it", fn)
+		}
+	}
+}
+
+// generatedFiles lists the committed files in dir that were created by the generator: the
+// json schemas, and the go files with the AnnounceTopic method of a discovery struct.
+func generatedFiles(dir string) ([]string, error) {
+	schemas, err := filepath.Glob(filepath.Join(dir, "schema", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sources, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fns := []string{}
+	for _, fn := range schemas {
+		fns = append(fns, "schema/"+filepath.Base(fn))
+	}
+	for _, fn := range sources {
+		bs, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(bs, []byte("// AnnounceTopic returns the topic to announce the discoverable")) {
+			fns = append(fns, filepath.Base(fn))
+		}
+	}
+	return fns, nil
+}
+
+func TestManifestOverrides(t *testing.T) {
+	tmpl, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := dirSource(snapshot).ReadFile("light")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := platform{
+		Component: "light",
+		Type:      "RenamedLight",
+		Fields: map[string]field{
+			"effect_list": {Name: "Effects", Type: "[]interface{}"},
+		},
+	}
+	got, err := generate(tmpl, p, bs)
+	if err != nil {
+		t.Fatalf("could not generate: %v", err)
+	}
+
+	for _, want := range []string{
+		"type RenamedLight struct",
+		"Effects []interface{} `json:\"effect_list,omitempty\"`",
+		`topicFormat := "%s/light/%s/config"`,
+	} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("generated source does not contain %q", want)
+		}
+	}
+
+	p.Fields = map[string]field{"not_a_key": {Name: "Nope"}}
+	if _, err := generate(tmpl, p, bs); err == nil {
+		t.Errorf("expected an error overriding an unknown key")
+	}
+}
+
+func TestPropertySchema(t *testing.T) {
+	m, err := readManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		e    entry
+		want schema
+	}{
+		{
+			e:    entry{Type: "float", GoType: getType("min_temp", "float"), Default: 7},
+			want: schema{"type": []string{"number", "string"}, "default": 7},
+		},
+		{
+			e:    entry{Type: "string", GoType: "string", Default: 7},
+			want: schema{"type": "string", "default": "7"},
+		},
+		{
+			e:    entry{Type: "integer", GoType: "*int", Default: 100},
+			want: schema{"type": "integer", "default": 100},
+		},
+	} {
+		if got := propertySchema(m, "climate", "key", &tc.e); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.e.Type, got, tc.want)
+		}
+	}
+}
+
+func TestTarball(t *testing.T) {
+	commit := "0123456789abcdef0123456789abcdef01234567"
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       "pax_global_header",
+		PAXRecords: map[string]string{"comment": commit},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	platforms := snapshotPlatforms(t)
+	for _, platform := range platforms {
+		bs, err := dirSource(snapshot).ReadFile(platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     "home-assistant.io-" + commit + "/source/_integrations/" + platform + suffix,
+			Mode:     0644,
+			Size:     int64(len(bs)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(bs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := readTarball(buf)
+	if err != nil {
+		t.Fatalf("could not read tarball: %v", err)
+	}
+	if ts.commit != commit {
+		t.Errorf("got commit %q, want %q", ts.commit, commit)
+	}
+
+	l, err := readLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := lockedSource{source: ts, lock: l}
+	for _, platform := range platforms {
+		if _, err := s.ReadFile(platform); err != nil {
+			t.Errorf("could not read %s from tarball: %v", platform, err)
+		}
+	}
+}
+
+// TestLockReproducible checks that the lock file is the one -update writes for the snapshot,
+// was not edited by hand, and pins the snapshot to an upstream commit.
+func TestLockReproducible(t *testing.T) {
+	l, err := readLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Commit == "" {
+		t.Errorf("lock file does not record the upstream commit, run go run ./generator -update <commit>")
+	}
+	ts, err := readDir(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &lockFile{
+		Repository: upstream,
+		Ref:        l.Ref,
+		Commit:     l.Commit,
+		Files:      make(map[string]string),
+	}
+	for fn, bs := range ts.files {
+		want.Files[fn] = checksum(bs)
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("lock file does not match the snapshot, run go run ./generator -update")
+	}
+
+	wantBS, err := want.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, wantBS) {
+		t.Errorf("lock file was not written by the generator")
+	}
+}
+
+func TestLockMismatch(t *testing.T) {
+	l, err := readLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform := snapshotPlatforms(t)[0]
+	l.Files[platform+suffix] = checksum([]byte("changed upstream"))
+
+	s := lockedSource{source: dirSource(snapshot), lock: l}
+	if _, err := s.ReadFile(platform); err == nil {
+		t.Errorf("expected an error reading %s with a mismatched checksum", platform)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	bs, err := dirSource(snapshot).ReadFile("binary_sensor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := platform{Component: "binary_sensor", Type: "BinarySensor"}
+	entries, err := platformEntries(p, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := readStruct(filepath.Join("..", p.fileName()), p.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := compare(p.Type, old, entries); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+
+	delete(entries, "off_delay")
+	entries["payload_on"].Default = "on"
+	entries["expire_after"].GoType = "string"
+	entries["name"].Required = true
+	entries["suggested_display_precision"] = &entry{GoType: "int"}
+
+	want := []change{
+		{Type: p.Type, Key: "expire_after", Kind: changeType, Old: "int", New: "string"},
+		{Type: p.Type, Key: "name", Kind: changeRequired, Old: "false", New: "true"},
+		{Type: p.Type, Key: "off_delay", Kind: changeRemoved, Old: "int"},
+		{Type: p.Type, Key: "payload_on", Kind: changeDefault, Old: "ON", New: "on"},
+		{Type: p.Type, Key: "suggested_display_precision", Kind: changeAdded, New: "int"},
+	}
+	got := compare(p.Type, old, entries)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got changes %+v, want %+v", got, want)
+	}
+
+	breaking := 0
+	for _, c := range got {
+		if c.Breaking() {
+			breaking++
+		}
+	}
+	if breaking != 3 {
+		t.Errorf("got %d breaking changes, want 3", breaking)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// upstream is the repository the integration documentation is taken from.
+const upstream = "home-assistant/home-assistant.io"
+
+const lockHeader = "# Code generated by generator -update; DO NOT EDIT.\n"
+
+// lockFile records the upstream commit a snapshot of the integrations was taken from, along
+// with the checksum of each file in the snapshot.
+type lockFile struct {
+	Repository string            `yaml:"repository"`
+	Ref        string            `yaml:"ref,omitempty"`
+	Commit     string            `yaml:"commit"`
+	Files      map[string]string `yaml:"files"`
+}
+
+func checksum(bs []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(bs))
+}
+
+func readLock(fn string) (*lockFile, error) {
+	bs, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("could not read lock file: %v", err)
+	}
+
+	l := &lockFile{}
+	err = yaml.Unmarshal(bs, l)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal lock file: %v", err)
+	}
+	return l, nil
+}
+
+func (l *lockFile) marshal() ([]byte, error) {
+	bs := &bytes.Buffer{}
+	bs.WriteString(lockHeader)
+
+	enc := yaml.NewEncoder(bs)
+	enc.SetIndent(2)
+	err := enc.Encode(l)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal lock file: %v", err)
+	}
+	return bs.Bytes(), nil
+}
+
+func (l *lockFile) write(fn string) error {
+	bs, err := l.marshal()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fn, bs, 0664)
+}
+
+// lockedSource is a source that only returns files matching the checksums in a lock file.
+type lockedSource struct {
+	source
+	lock *lockFile
+}
+
+func (l lockedSource) ReadFile(platform string) ([]byte, error) {
+	bs, err := l.source.ReadFile(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	want, ok := l.lock.Files[platform+suffix]
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the lock file", platform+suffix)
+	}
+	if got := checksum(bs); got != want {
+		return nil, fmt.Errorf("%s does not match the lock file: got %s, want %s", platform+suffix, got, want)
+	}
+	return bs, nil
+}
+
+// tarballURL returns the url of a tarball of the upstream repository at ref.
+func tarballURL(ref string) string {
+	return fmt.Sprintf("https://codeload.github.com/%s/tar.gz/%s", upstream, ref)
+}
+
+// update replaces the snapshot in dir with the integrations from src and records them in the
+// lock file. src is a path or url of a tarball, a ref of the upstream repository, or a
+// directory of integrations. The commit is unknown for a directory, so it is left empty;
+// updating from dir itself rewrites the lock file for the snapshot as it is.
+func update(src, dir, lockPath string) error {
+	var ts *tarSource
+	var err error
+	ref := ""
+	switch {
+	case isTarball(src) || isURL(src):
+		ts, err = openTarball(src)
+	case isDir(src):
+		ts, err = readDir(src)
+	default:
+		ref = src
+		ts, err = openTarball(tarballURL(ref))
+	}
+	if err != nil {
+		return err
+	}
+
+	old, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+	if err != nil {
+		return err
+	}
+	for _, fn := range old {
+		err = os.Remove(fn)
+		if err != nil {
+			return fmt.Errorf("could not remove old snapshot: %v", err)
+		}
+	}
+
+	err = os.MkdirAll(dir, 0775)
+	if err != nil {
+		return fmt.Errorf("could not create snapshot directory: %v", err)
+	}
+
+	l := &lockFile{
+		Repository: upstream,
+		Ref:        ref,
+		Commit:     ts.commit,
+		Files:      make(map[string]string),
+	}
+
+	for fn, bs := range ts.files {
+		err = ioutil.WriteFile(filepath.Join(dir, fn), bs, 0664)
+		if err != nil {
+			return fmt.Errorf("could not write snapshot: %v", err)
+		}
+		l.Files[fn] = checksum(bs)
+	}
+
+	return l.write(lockPath)
+}
@@ -0,0 +1,112 @@
+// Command generator creates the discovery structs from the configuration documented for each
+// MQTT integration in the home-assistant.io repository. The platforms to generate, and the
+// shared files such as the component names, abbreviations and enums, are listed in
+// generator/manifest.yaml. Adding a platform is a matter of adding it to the manifest.
+//
+// By default the integrations are read from the snapshot in generator/testdata/_integrations,
+// which is checked against generator/upstream.lock so the output does not depend on the
+// network or on changes upstream. Refresh the snapshot with:
+//
+//	go run ./generator -update current
+//
+// After refreshing, -drift reports the keys that were added, removed, or had their type,
+// default or required flag changed upstream, before any files are regenerated:
+//
+//	go run ./generator -drift -fail-on-breaking
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	manifestPath := flag.String("manifest", "generator/manifest.yaml", "manifest listing what to generate")
+	src := flag.String("src", "generator/testdata/_integrations", "directory, tarball or url to read the integrations from")
+	lockPath := flag.String("lock", "generator/upstream.lock", "lock file to verify the integrations against, empty to skip")
+	out := flag.String("out", ".", "directory to write the generated files to")
+	drifts := flag.Bool("drift", false, "report the differences between the integrations and the generated files instead of generating")
+	asJSON := flag.Bool("json", false, "write the drift report as json")
+	failBreaking := flag.Bool("fail-on-breaking", false, "exit with an error if the drift report has breaking changes")
+	upd := flag.String("update", "", "ref, tarball, tarball url or directory to refresh the snapshot in -src and the lock file from")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *upd != "" {
+		err := update(*upd, *src, *lockPath)
+		if err != nil {
+			log.Fatalf("could not update snapshot: %v", err)
+		}
+		return
+	}
+
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	m, err := readManifest(*manifestPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := openSource(*src)
+	if err != nil {
+		log.Fatalf("could not open source: %v", err)
+	}
+	if *lockPath != "" {
+		l, err := readLock(*lockPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s = lockedSource{source: s, lock: l}
+	}
+
+	if *drifts {
+		changes, err := drift(m, s, *out)
+		if err != nil {
+			log.Fatalf("could not compare: %v", err)
+		}
+		err = writeReport(os.Stdout, changes, *asJSON)
+		if err != nil {
+			log.Fatalf("could not write report: %v", err)
+		}
+		if *failBreaking {
+			for _, c := range changes {
+				if c.Breaking() {
+					log.Fatalf("upstream has breaking changes")
+				}
+			}
+		}
+		return
+	}
+
+	t, err := loadTemplates()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files, err := run(t, m, s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for fn, bs := range files {
+		fn = filepath.Join(*out, filepath.FromSlash(fn))
+		err = os.MkdirAll(filepath.Dir(fn), 0775)
+		if err != nil {
+			log.Fatalf("could not create directory: %v", err)
+		}
+		err = ioutil.WriteFile(fn, bs, 0664)
+		if err != nil {
+			log.Fatalf("could not write file: %v", err)
+		}
+	}
+}
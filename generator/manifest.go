@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifest lists everything generated in a single run of the generator.
+type manifest struct {
+	Platforms []platform `yaml:"platforms"`
+	Enums     []enum     `yaml:"enums,omitempty"`
+	// Abbreviations maps configuration keys to the abbreviations Home Assistant accepts in
+	// discovery payloads.
+	Abbreviations map[string]string `yaml:"abbreviations,omitempty"`
+	// DeviceAbbreviations maps the keys of the device map to their abbreviations.
+	DeviceAbbreviations map[string]string `yaml:"device_abbreviations,omitempty"`
+}
+
+// platform is a discovery struct to generate from an integration.
+type platform struct {
+	// Component is the name of the integration and the component in the discovery topic.
+	Component string `yaml:"component"`
+	// Type is the name of the generated struct. Defaults to the component in CamelCase.
+	Type string `yaml:"type,omitempty"`
+	// Section is the index of the configuration section to use, for integrations that
+	// document a configuration for each schema.
+	Section int `yaml:"section,omitempty"`
+	// Fields overrides the generated name or type of configuration keys.
+	Fields map[string]field `yaml:"fields,omitempty"`
+}
+
+type field struct {
+	Name string `yaml:"name,omitempty"`
+	Type string `yaml:"type,omitempty"`
+}
+
+// enum is a set of string constants for the valid values of a configuration key.
+type enum struct {
+	Name string `yaml:"name"`
+	Doc  string `yaml:"doc"`
+	// Typed declares a named string type for the constants rather than untyped constants.
+	Typed  bool     `yaml:"typed,omitempty"`
+	Values []string `yaml:"values"`
+	// Keys are the configuration keys the values apply to, either as key for every platform
+	// or as component.key for a single platform.
+	Keys []string `yaml:"keys,omitempty"`
+}
+
+func readManifest(fn string) (*manifest, error) {
+	bs, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %v", err)
+	}
+
+	m := &manifest{}
+	err = yaml.Unmarshal(bs, m)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal manifest: %v", err)
+	}
+
+	types := make(map[string]bool)
+	for i, p := range m.Platforms {
+		if p.Component == "" {
+			return nil, fmt.Errorf("platform %d has no component", i)
+		}
+		if p.Type == "" {
+			m.Platforms[i].Type = convertKey(p.Component)
+		}
+		if types[m.Platforms[i].Type] {
+			return nil, fmt.Errorf("type %s is generated more than once", m.Platforms[i].Type)
+		}
+		types[m.Platforms[i].Type] = true
+	}
+	for _, e := range m.Enums {
+		if e.Name == "" || e.Doc == "" || len(e.Values) == 0 {
+			return nil, fmt.Errorf("enum %q needs a name, doc and values", e.Name)
+		}
+	}
+
+	return m, nil
+}
+
+// fileName returns the name of the go file generated for the platform.
+func (p platform) fileName() string {
+	return strings.ToLower(p.Type) + ".go"
+}
+
+// Components returns the components in the manifest, sorted and without duplicates.
+func (m *manifest) Components() []string {
+	seen := make(map[string]bool)
+	cs := []string{}
+	for _, p := range m.Platforms {
+		if !seen[p.Component] {
+			seen[p.Component] = true
+			cs = append(cs, p.Component)
+		}
+	}
+	sort.Strings(cs)
+	return cs
+}
+
+// enumConst returns the name of the constant for an enum value.
+func enumConst(name, value string) string {
+	return name + convertKey(strings.ReplaceAll(value, "-", "_"))
+}
+
+type abbreviation struct {
+	Key          string
+	Abbreviation string
+}
+
+// sortedAbbreviations returns the abbreviations sorted by key.
+func sortedAbbreviations(m map[string]string) []abbreviation {
+	as := make([]abbreviation, 0, len(m))
+	for k, v := range m {
+		as = append(as, abbreviation{Key: k, Abbreviation: v})
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].Key < as[j].Key })
+	return as
+}
+
+// sharedData is passed to the templates of the files generated once from the whole manifest.
+type sharedData struct {
+	*manifest
+	entries map[string]map[string]*entry
+}
+
+// registryEntry describes a component in the generated registry.
+type registryEntry struct {
+	Component        string
+	Type             string
+	Fields           []string
+	HasCommandTopic  bool
+	HasStateTopic    bool
+	SupportsUniqueID bool
+}
+
+// Registry returns an entry for each component, sorted by component. Components with several
+// platforms use the first platform in the manifest.
+func (sd sharedData) Registry() []registryEntry {
+	seen := make(map[string]bool)
+	rs := []registryEntry{}
+	for _, p := range sd.Platforms {
+		if seen[p.Component] {
+			continue
+		}
+		seen[p.Component] = true
+
+		es := sd.entries[p.Type]
+		r := registryEntry{Component: p.Component, Type: p.Type}
+		for k := range es {
+			r.Fields = append(r.Fields, k)
+		}
+		sort.Strings(r.Fields)
+		_, r.HasCommandTopic = es["command_topic"]
+		_, r.HasStateTopic = es["state_topic"]
+		_, r.SupportsUniqueID = es["unique_id"]
+		rs = append(rs, r)
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Component < rs[j].Component })
+	return rs
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaDir is the directory the json schemas are written to, relative to the output.
+const schemaDir = "schema"
+
+type schema map[string]interface{}
+
+// schemaDefs are the definitions shared by the platform schemas, matching the hand written
+// Availability and Device structs.
+func schemaDefs() schema {
+	str := func(desc string) schema { return schema{"type": "string", "description": desc} }
+	return schema{
+		"availability": schema{
+			"type":     "object",
+			"required": []string{"topic"},
+			"properties": schema{
+				"payload_available":     schema{"type": "string", "description": "The payload that represents the available state.", "default": "online"},
+				"payload_not_available": schema{"type": "string", "description": "The payload that represents the unavailable state.", "default": "offline"},
+				"topic":                 str("An MQTT topic subscribed to receive availability (online/offline) updates."),
+			},
+		},
+		"device": schema{
+			"type": "object",
+			"properties": schema{
+				"connections": schema{
+					"type":        "array",
+					"description": "A list of connections of the device to the outside world as a list of tuples [connection_type, connection_identifier].",
+					"items": schema{
+						"type":        "array",
+						"prefixItems": []schema{{"type": "string"}, {"type": "string"}},
+						"minItems":    2,
+						"maxItems":    2,
+					},
+				},
+				"identifiers":    schema{"type": "array", "description": "A list of IDs that uniquely identify the device.", "items": schema{"type": "string"}},
+				"manufacturer":   str("The manufacturer of the device."),
+				"model":          str("The model of the device."),
+				"name":           str("The name of the device."),
+				"suggested_area": str("Suggest an area if the device isn't in one yet."),
+				"sw_version":     str("The firmware version of the device."),
+				"via_device":     str("Identifier of a device that routes messages between this device and Home Assistant."),
+			},
+		},
+	}
+}
+
+// enum returns the enum with the given name.
+func (m *manifest) enum(name string) (enum, bool) {
+	for _, e := range m.Enums {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return enum{}, false
+}
+
+// enumValues returns the values of the enum that applies to the key of the component, if any.
+func (m *manifest) enumValues(component, key string) []string {
+	for _, e := range m.Enums {
+		for _, k := range e.Keys {
+			if k == key || k == component+"."+key {
+				return e.Values
+			}
+		}
+	}
+	return nil
+}
+
+// propertySchema returns the schema of a single configuration entry.
+func propertySchema(m *manifest, component, key string, e *entry) schema {
+	s := schema{}
+	switch e.GoType {
+	case "string":
+		s["type"] = "string"
+		// floats are generated as strings, and Home Assistant accepts both
+		if e.Type == "float" {
+			s["type"] = []string{"number", "string"}
+		}
+	case "int", "*int":
+		s["type"] = "integer"
+	case "float64":
+		s["type"] = "number"
+	case "bool", "*bool":
+		s["type"] = "boolean"
+	case "[]string":
+		s["type"] = "array"
+		s["items"] = schema{"type": "string"}
+	case "[]Availability":
+		s["type"] = "array"
+		s["items"] = schema{"$ref": "#/$defs/availability"}
+	case "*Device":
+		s["$ref"] = "#/$defs/device"
+	default:
+		// fields overridden with a typed enum, or a list of them
+		if en, ok := m.enum(strings.TrimPrefix(e.GoType, "[]")); ok && en.Typed {
+			if strings.HasPrefix(e.GoType, "[]") {
+				s["type"] = "array"
+				s["items"] = schema{"type": "string", "enum": en.Values}
+			} else {
+				s["type"] = "string"
+				s["enum"] = en.Values
+			}
+		}
+	}
+
+	if d := strings.TrimSpace(e.Description); d != "" {
+		s["description"] = d
+	}
+
+	if values := m.enumValues(component, key); values != nil {
+		if s["type"] == "array" {
+			s["items"] = schema{"type": "string", "enum": values}
+		} else {
+			s["enum"] = values
+		}
+	}
+
+	if def, ok := schemaDefault(s["type"], e.Default); ok {
+		s["default"] = def
+	}
+
+	return s
+}
+
+// schemaDefault converts a documented default to the type of the property. Defaults that
+// cannot be represented in the type are dropped.
+func schemaDefault(ty interface{}, def interface{}) (interface{}, bool) {
+	switch d := def.(type) {
+	case nil:
+		return nil, false
+	case []interface{}:
+		return d, ty == "array"
+	case string:
+		return d, ty == "string"
+	}
+
+	if tys, ok := ty.([]string); ok {
+		for _, t := range tys {
+			if d, ok := schemaDefault(t, def); ok {
+				return d, true
+			}
+		}
+		return nil, false
+	}
+
+	switch ty {
+	case "string":
+		return fmt.Sprint(def), true
+	case "integer":
+		_, ok := def.(int)
+		return def, ok
+	case "number":
+		switch def.(type) {
+		case int, float64:
+			return def, true
+		}
+	case "boolean":
+		_, ok := def.(bool)
+		return def, ok
+	}
+	return nil, false
+}
+
+// platformSchema returns the schema of the discovery payload for the platform.
+func platformSchema(m *manifest, p platform, entries map[string]*entry) schema {
+	props := schema{}
+	required := []string{}
+	for k, e := range entries {
+		props[k] = propertySchema(m, p.Component, k, e)
+		if e.Required {
+			required = append(required, k)
+		}
+	}
+
+	s := schema{
+		"title":      p.Type,
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+// componentSchema combines the schemas of the platforms of a component.
+func componentSchema(component string, ss []schema) schema {
+	if len(ss) == 1 {
+		return ss[0]
+	}
+	return schema{"title": convertKey(component), "oneOf": ss}
+}
+
+// deviceSchema returns the schema of a device discovery payload, in which the configuration of
+// each component is keyed by its object id.
+func deviceSchema(components []string) schema {
+	conditions := []schema{}
+	for _, c := range components {
+		conditions = append(conditions, schema{
+			"if":   schema{"properties": schema{"platform": schema{"const": c}}},
+			"then": schema{"$ref": "#/$defs/" + c},
+		})
+	}
+
+	return schema{
+		"title":    "Device discovery",
+		"type":     "object",
+		"required": []string{"components", "device", "origin"},
+		"properties": schema{
+			"availability":       schema{"type": "array", "items": schema{"$ref": "#/$defs/availability"}},
+			"availability_topic": schema{"type": "string"},
+			"command_topic":      schema{"type": "string"},
+			"components": schema{
+				"type":        "object",
+				"description": "The configuration of each component of the device, keyed by object id.",
+				"additionalProperties": schema{
+					"type":     "object",
+					"required": []string{"platform"},
+					"properties": schema{
+						"platform": schema{"enum": components},
+					},
+					"allOf": conditions,
+				},
+			},
+			"device":   schema{"$ref": "#/$defs/device"},
+			"encoding": schema{"type": "string"},
+			"origin": schema{
+				"type":     "object",
+				"required": []string{"name"},
+				"properties": schema{
+					"name":        schema{"type": "string", "description": "The name of the application that is the origin of the discovered MQTT item."},
+					"support_url": schema{"type": "string", "description": "Support URL of the application that supplies the discovered MQTT item."},
+					"sw_version":  schema{"type": "string", "description": "Software version of the application that supplies the discovered MQTT item."},
+				},
+			},
+			"qos":         schema{"type": "integer", "enum": []int{0, 1, 2}},
+			"state_topic": schema{"type": "string"},
+		},
+	}
+}
+
+// schemas returns the json schema of each component, and of the device discovery payload,
+// keyed by file name.
+func schemas(m *manifest, entries map[string]map[string]*entry) (map[string][]byte, error) {
+	byComponent := make(map[string][]schema)
+	for _, p := range m.Platforms {
+		byComponent[p.Component] = append(byComponent[p.Component], platformSchema(m, p, entries[p.Type]))
+	}
+
+	files := make(map[string][]byte)
+	deviceDefs := schemaDefs()
+	for c, ss := range byComponent {
+		s := componentSchema(c, ss)
+		deviceDefs[c] = s
+
+		doc := schema{"$schema": schemaDraft, "$defs": schemaDefs()}
+		for k, v := range s {
+			doc[k] = v
+		}
+
+		bs, err := marshalSchema(doc)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal schema for %s: %v", c, err)
+		}
+		files[schemaDir+"/"+c+".json"] = bs
+	}
+
+	doc := deviceSchema(m.Components())
+	doc["$schema"] = schemaDraft
+	doc["$defs"] = deviceDefs
+	bs, err := marshalSchema(doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal device schema: %v", err)
+	}
+	files[schemaDir+"/device.json"] = bs
+
+	return files, nil
+}
+
+func marshalSchema(s schema) ([]byte, error) {
+	bs, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bs, '\n'), nil
+}
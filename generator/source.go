@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// suffix is appended to a platform name to get the name of its integration markdown.
+const suffix = ".mqtt.markdown"
+
+// source provides the integration markdown for a platform.
+type source interface {
+	ReadFile(platform string) ([]byte, error)
+}
+
+// dirSource reads integrations from a local copy of the home-assistant.io _integrations
+// directory.
+type dirSource string
+
+func (d dirSource) ReadFile(platform string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), platform+suffix))
+}
+
+// urlSource reads integrations over http from a base url, for example
+// https://raw.githubusercontent.com/home-assistant/home-assistant.io/current/source/_integrations
+type urlSource string
+
+func (u urlSource) ReadFile(platform string) ([]byte, error) {
+	rc, err := fetch(strings.TrimSuffix(string(u), "/") + "/" + platform + suffix)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
+
+// tarSource holds the integrations read from a tarball of the home-assistant.io repository.
+type tarSource struct {
+	// commit is the upstream commit the tarball was created from, if it is known.
+	commit string
+	files  map[string][]byte
+}
+
+func (t *tarSource) ReadFile(platform string) ([]byte, error) {
+	bs, ok := t.files[platform+suffix]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in tarball", platform+suffix)
+	}
+	return bs, nil
+}
+
+// readTarball reads all MQTT integrations from a (gzipped) tarball. Tarballs created by
+// github or git archive record the commit in the pax global header.
+func readTarball(r io.Reader) (*tarSource, error) {
+	buf := bufio.NewReader(r)
+	magic, err := buf.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tarball: %v", err)
+	}
+
+	var br io.Reader = buf
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress tarball: %v", err)
+		}
+		defer gz.Close()
+		br = gz
+	}
+
+	ts := &tarSource{files: make(map[string][]byte)}
+	tr := tar.NewReader(br)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read tarball: %v", err)
+		}
+
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			ts.commit = hdr.PAXRecords["comment"]
+			continue
+		}
+
+		dir, fn := path.Split(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || path.Base(dir) != "_integrations" || !strings.HasSuffix(fn, suffix) {
+			continue
+		}
+
+		fbs, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", hdr.Name, err)
+		}
+		ts.files[fn] = fbs
+	}
+
+	if len(ts.files) == 0 {
+		return nil, fmt.Errorf("no integrations found in tarball")
+	}
+
+	return ts, nil
+}
+
+// readDir reads all MQTT integrations from a directory. The commit they come from is not
+// known.
+func readDir(dir string) (*tarSource, error) {
+	fns, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+	if err != nil {
+		return nil, err
+	}
+	if len(fns) == 0 {
+		return nil, fmt.Errorf("no integrations found in %s", dir)
+	}
+
+	ts := &tarSource{files: make(map[string][]byte)}
+	for _, fn := range fns {
+		bs, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", fn, err)
+		}
+		ts.files[filepath.Base(fn)] = bs
+	}
+	return ts, nil
+}
+
+func isDir(src string) bool {
+	fi, err := os.Stat(src)
+	return err == nil && fi.IsDir()
+}
+
+func isURL(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+func isTarball(src string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(src, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func fetch(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not get url: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("could not get url: %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// openTarball reads a tarball from a path or url.
+func openTarball(src string) (*tarSource, error) {
+	var rc io.ReadCloser
+	var err error
+	if isURL(src) {
+		rc, err = fetch(src)
+	} else {
+		rc, err = os.Open(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return readTarball(rc)
+}
+
+// openSource returns the source for src, which can be a directory, a tarball or a url.
+// Tarballs are recognised by their extension.
+func openSource(src string) (source, error) {
+	switch {
+	case isTarball(src):
+		return openTarball(src)
+	case isURL(src):
+		return urlSource(src), nil
+	}
+
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory or tarball", src)
+	}
+	return dirSource(src), nil
+}
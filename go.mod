@@ -2,4 +2,4 @@ module github.com/duncanvanzyl/hass-discovery
 
 go 1.17
 
-require gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+require gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
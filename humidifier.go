@@ -106,7 +106,7 @@ type Humidifier struct {
 
 	// List of available modes this humidifier is capable of running at. Common examples include `normal`, `eco`, `away`, `boost`, `comfort`, `home`, `sleep`, `auto` and `baby`. These examples offer built-in translations but other custom modes are allowed as well.  This attribute ust be configured together with the `mode_command_topic` attribute
 	// Default: []
-	Modes string `json:"modes,omitempty"`
+	Modes []string `json:"modes,omitempty"`
 
 	// The name of the humidifier. Can be set to `null` if only the device name is relevant
 	// Default: MQTT humidifier
@@ -187,7 +187,9 @@ type Humidifier struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Humidifier
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Humidifier
 func (d *Humidifier) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/humidifier/%s/config"
@@ -0,0 +1,373 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default target humidity range of a Humidifier.
+const (
+	DefaultHumidifierMinHumidity = 0
+	DefaultHumidifierMaxHumidity = 100
+)
+
+// DefaultHumidityTolerance is the tolerance of Home Assistant's generic hygrostat.
+const DefaultHumidityTolerance = 3
+
+// HumidifierState is the state of a humidifier or dehumidifier.
+type HumidifierState struct {
+	On     bool
+	Action HumidifierAction
+
+	// Mode is empty when it is unknown, which is published as the PayloadResetMode.
+	Mode string
+
+	// TargetHumidity and CurrentHumidity are only valid once HasTargetHumidity and
+	// HasCurrentHumidity are true. Until then they are published as the PayloadResetHumidity.
+	TargetHumidity     float64
+	HasTargetHumidity  bool
+	CurrentHumidity    float64
+	HasCurrentHumidity bool
+}
+
+// HumidistatController decides what a humidifier should be doing. It is called whenever the
+// state changes, with the action it is currently doing in the state.
+type HumidistatController interface {
+	Action(s HumidifierState) HumidifierAction
+}
+
+// HysteresisHumidistat is a HumidistatController that humidifies, or dries when it is a
+// Dehumidifier, once the humidity has drifted past the target by a tolerance, and keeps going
+// until it has passed the target by the other tolerance, like Home Assistant's generic
+// hygrostat.
+type HysteresisHumidistat struct {
+	Dehumidifier bool
+	// DryTolerance is how far below the target the humidity must fall for humidifying to
+	// start, and for drying to stop.
+	DryTolerance float64
+	// WetTolerance is how far above the target the humidity must rise for drying to start,
+	// and for humidifying to stop.
+	WetTolerance float64
+}
+
+// Action implements HumidistatController.
+func (h HysteresisHumidistat) Action(s HumidifierState) HumidifierAction {
+	if !s.On {
+		return HumidifierActionOff
+	}
+	if !s.HasCurrentHumidity || !s.HasTargetHumidity {
+		return HumidifierActionIdle
+	}
+
+	c, target := s.CurrentHumidity, s.TargetHumidity
+	if h.Dehumidifier {
+		if s.Action == HumidifierActionDrying && c > target-h.DryTolerance ||
+			c >= target+h.WetTolerance {
+			return HumidifierActionDrying
+		}
+		return HumidifierActionIdle
+	}
+	if s.Action == HumidifierActionHumidifying && c < target+h.WetTolerance ||
+		c <= target-h.DryTolerance {
+		return HumidifierActionHumidifying
+	}
+	return HumidifierActionIdle
+}
+
+// HumidifierController is a humidifier bound to the command and state topics of a
+// Humidifier. It tracks the state, mode and target humidity, handles the commands from Home
+// Assistant, and publishes the state. Its Controller decides the HumidifierAction from the
+// current humidity. Any mode is accepted if the Humidifier has no Modes.
+type HumidifierController struct {
+	Humidifier *Humidifier
+	Publisher  Publisher
+	Controller HumidistatController
+
+	MinHumidity float64
+	MaxHumidity float64
+
+	// OnChange is called with the new state when a command from Home Assistant changes it.
+	// The state only changes if it succeeds. It is called with the controller locked, so it
+	// must not call the controller's methods.
+	OnChange func(HumidifierState) error
+	// OnAction is called when the action changes, to switch the humidifier or dryer.
+	OnAction func(HumidifierAction)
+	// OnError is called with the errors from handling commands.
+	OnError func(error)
+
+	mu    sync.Mutex
+	state HumidifierState
+}
+
+// NewHumidifierController creates a HumidifierController for h that publishes with p. It
+// starts off, with the mode and target humidity unknown. The Controller dries when the
+// DeviceClass of h is dehumidifier, and humidifies otherwise.
+func NewHumidifierController(h *Humidifier, p Publisher) (*HumidifierController, error) {
+	c := &HumidifierController{
+		Humidifier: h,
+		Publisher:  p,
+		Controller: HysteresisHumidistat{
+			Dehumidifier: h.DeviceClass == HumidifierDeviceClassDehumidifier,
+			DryTolerance: DefaultHumidityTolerance,
+			WetTolerance: DefaultHumidityTolerance,
+		},
+		MinHumidity: DefaultHumidifierMinHumidity,
+		MaxHumidity: DefaultHumidifierMaxHumidity,
+		state:       HumidifierState{Action: HumidifierActionOff},
+	}
+	for _, l := range []struct {
+		value string
+		v     *float64
+	}{
+		{h.MinHumidity, &c.MinHumidity},
+		{h.MaxHumidity, &c.MaxHumidity},
+	} {
+		if l.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(l.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse humidity limit: %v", err)
+		}
+		*l.v = f
+	}
+	if c.MinHumidity > c.MaxHumidity {
+		return nil, fmt.Errorf("minimum humidity %v is above the maximum %v", c.MinHumidity, c.MaxHumidity)
+	}
+	return c, nil
+}
+
+// State returns the current state.
+func (c *HumidifierController) State() HumidifierState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Publish publishes the whole state to the configured state topics.
+func (c *HumidifierController) Publish() error {
+	s := c.State()
+	for _, p := range []struct {
+		topic   string
+		payload string
+	}{
+		{c.Humidifier.StateTopic, c.power(s.On)},
+		{c.Humidifier.ActionTopic, string(s.Action)},
+		{c.Humidifier.ModeStateTopic, c.mode(s.Mode)},
+		{c.Humidifier.TargetHumidityStateTopic, c.humidity(s.TargetHumidity, s.HasTargetHumidity)},
+		{c.Humidifier.CurrentHumidityTopic, c.humidity(s.CurrentHumidity, s.HasCurrentHumidity)},
+	} {
+		if err := c.publish(p.topic, p.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *HumidifierController) power(on bool) string {
+	if on {
+		return orDefault(c.Humidifier.PayloadOn, "ON")
+	}
+	return orDefault(c.Humidifier.PayloadOff, "OFF")
+}
+
+func (c *HumidifierController) mode(mode string) string {
+	return orDefault(mode, orDefault(c.Humidifier.PayloadResetMode, DefaultPayloadReset))
+}
+
+func (c *HumidifierController) humidity(h float64, ok bool) string {
+	if !ok {
+		return orDefault(c.Humidifier.PayloadResetHumidity, DefaultPayloadReset)
+	}
+	return formatHumidity(h)
+}
+
+func (c *HumidifierController) publish(topic, payload string) error {
+	if topic == "" {
+		return nil
+	}
+	err := c.Publisher.Publish(topic, byte(c.Humidifier.Qos), c.Humidifier.Retain, []byte(payload))
+	if err != nil {
+		return fmt.Errorf("could not publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+// update applies change to a copy of the state, and if check accepts the new state, stores
+// it, runs the controller, and publishes what changed. The change is checked and stored
+// without releasing the lock, so a concurrent update can't make the check out of date.
+func (c *HumidifierController) update(change func(*HumidifierState), check func(HumidifierState) error) error {
+	var old, s HumidifierState
+	return updateState(&c.mu, func() error {
+		old, s = c.state, c.state
+		change(&s)
+		if check != nil {
+			if err := check(s); err != nil {
+				return err
+			}
+		}
+		s.Action = c.Controller.Action(s)
+		c.state = s
+		return nil
+	}, func() error {
+		if s.Action != old.Action && c.OnAction != nil {
+			c.OnAction(s.Action)
+		}
+		return c.publishChanges(old, s)
+	})
+}
+
+// publishChanges publishes the parts of the state that changed.
+func (c *HumidifierController) publishChanges(old, s HumidifierState) error {
+	for _, p := range []struct {
+		changed bool
+		topic   string
+		payload string
+	}{
+		{old.On != s.On, c.Humidifier.StateTopic, c.power(s.On)},
+		{old.Action != s.Action, c.Humidifier.ActionTopic, string(s.Action)},
+		{old.Mode != s.Mode, c.Humidifier.ModeStateTopic, c.mode(s.Mode)},
+		{
+			old.TargetHumidity != s.TargetHumidity || old.HasTargetHumidity != s.HasTargetHumidity,
+			c.Humidifier.TargetHumidityStateTopic,
+			c.humidity(s.TargetHumidity, s.HasTargetHumidity),
+		},
+		{
+			old.CurrentHumidity != s.CurrentHumidity || old.HasCurrentHumidity != s.HasCurrentHumidity,
+			c.Humidifier.CurrentHumidityTopic,
+			c.humidity(s.CurrentHumidity, s.HasCurrentHumidity),
+		},
+	} {
+		if !p.changed {
+			continue
+		}
+		if err := c.publish(p.topic, p.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// command updates the state with a change requested by Home Assistant, which OnChange must
+// accept.
+func (c *HumidifierController) command(change func(*HumidifierState)) error {
+	return c.update(change, c.OnChange)
+}
+
+// SetCurrentHumidity sets the measured humidity, and runs the controller.
+func (c *HumidifierController) SetCurrentHumidity(h float64) error {
+	return c.update(func(s *HumidifierState) {
+		s.CurrentHumidity = h
+		s.HasCurrentHumidity = true
+	}, nil)
+}
+
+// ResetCurrentHumidity makes the measured humidity unknown, for example when the sensor is
+// lost. The controller goes idle until it is set again.
+func (c *HumidifierController) ResetCurrentHumidity() error {
+	return c.update(func(s *HumidifierState) {
+		s.CurrentHumidity = 0
+		s.HasCurrentHumidity = false
+	}, nil)
+}
+
+// SetOn turns the humidifier on or off, for example when it is switched on the device.
+func (c *HumidifierController) SetOn(on bool) error {
+	return c.update(func(s *HumidifierState) { s.On = on }, nil)
+}
+
+// SetMode sets the mode, for example when it is changed on the device. An empty mode makes it
+// unknown.
+func (c *HumidifierController) SetMode(mode string) error {
+	if mode != "" && !c.supported(mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+	return c.update(func(s *HumidifierState) { s.Mode = mode }, nil)
+}
+
+// SetTargetHumidity sets the target humidity, for example when it is changed on the device.
+func (c *HumidifierController) SetTargetHumidity(h float64) error {
+	if err := c.checkHumidity(h); err != nil {
+		return err
+	}
+	return c.update(func(s *HumidifierState) {
+		s.TargetHumidity = h
+		s.HasTargetHumidity = true
+	}, nil)
+}
+
+func (c *HumidifierController) supported(mode string) bool {
+	return len(c.Humidifier.Modes) == 0 || contains(c.Humidifier.Modes, mode)
+}
+
+func (c *HumidifierController) checkHumidity(h float64) error {
+	if h < c.MinHumidity || h > c.MaxHumidity {
+		return fmt.Errorf("humidity %v is outside %v to %v", h, c.MinHumidity, c.MaxHumidity)
+	}
+	return nil
+}
+
+// Subscribe subscribes the controller to the Humidifier's command topics.
+func (c *HumidifierController) Subscribe(s Subscriber) error {
+	for _, sub := range []struct {
+		topic   string
+		handler func(Message) error
+	}{
+		{c.Humidifier.CommandTopic, c.HandleState},
+		{c.Humidifier.ModeCommandTopic, c.HandleMode},
+		{c.Humidifier.TargetHumidityCommandTopic, c.HandleTargetHumidity},
+	} {
+		if sub.topic == "" {
+			continue
+		}
+		handler := sub.handler
+		err := s.Subscribe(sub.topic, byte(c.Humidifier.Qos), func(m Message) {
+			if err := handler(m); err != nil && c.OnError != nil {
+				c.OnError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", sub.topic, err)
+		}
+	}
+	return nil
+}
+
+// HandleState handles a message on the CommandTopic.
+func (c *HumidifierController) HandleState(m Message) error {
+	var on bool
+	switch strings.TrimSpace(string(m.Payload)) {
+	case orDefault(c.Humidifier.PayloadOn, "ON"):
+		on = true
+	case orDefault(c.Humidifier.PayloadOff, "OFF"):
+	default:
+		return fmt.Errorf("unknown state payload %q", m.Payload)
+	}
+	return c.command(func(s *HumidifierState) { s.On = on })
+}
+
+// HandleMode handles a message on the ModeCommandTopic.
+func (c *HumidifierController) HandleMode(m Message) error {
+	mode := strings.TrimSpace(string(m.Payload))
+	if mode == "" || !c.supported(mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+	return c.command(func(s *HumidifierState) { s.Mode = mode })
+}
+
+// HandleTargetHumidity handles a message on the TargetHumidityCommandTopic.
+func (c *HumidifierController) HandleTargetHumidity(m Message) error {
+	h, err := strconv.ParseFloat(strings.TrimSpace(string(m.Payload)), 64)
+	if err != nil {
+		return fmt.Errorf("could not parse humidity: %v", err)
+	}
+	if err := c.checkHumidity(h); err != nil {
+		return err
+	}
+	return c.command(func(s *HumidifierState) {
+		s.TargetHumidity = h
+		s.HasTargetHumidity = true
+	})
+}
@@ -0,0 +1,151 @@
+package discovery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHysteresisHumidistat(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		h     HysteresisHumidistat
+		steps []struct {
+			current float64
+			want    HumidifierAction
+		}
+	}{
+		{
+			name: "humidifier",
+			h:    HysteresisHumidistat{DryTolerance: 3, WetTolerance: 3},
+			steps: []struct {
+				current float64
+				want    HumidifierAction
+			}{
+				{48, HumidifierActionIdle},
+				{47, HumidifierActionHumidifying},
+				{52, HumidifierActionHumidifying},
+				{53, HumidifierActionIdle},
+				{48, HumidifierActionIdle},
+			},
+		},
+		{
+			name: "dehumidifier",
+			h:    HysteresisHumidistat{Dehumidifier: true, DryTolerance: 3, WetTolerance: 3},
+			steps: []struct {
+				current float64
+				want    HumidifierAction
+			}{
+				{52, HumidifierActionIdle},
+				{53, HumidifierActionDrying},
+				{48, HumidifierActionDrying},
+				{47, HumidifierActionIdle},
+				{52, HumidifierActionIdle},
+			},
+		},
+	} {
+		s := HumidifierState{On: true, TargetHumidity: 50, HasTargetHumidity: true, HasCurrentHumidity: true}
+		for _, step := range tc.steps {
+			s.CurrentHumidity = step.current
+			s.Action = tc.h.Action(s)
+			if s.Action != step.want {
+				t.Errorf("%s at %v: got %s, want %s", tc.name, step.current, s.Action, step.want)
+			}
+		}
+	}
+
+	h := HysteresisHumidistat{}
+	if got := h.Action(HumidifierState{CurrentHumidity: 10, HasCurrentHumidity: true, HasTargetHumidity: true, TargetHumidity: 50}); got != HumidifierActionOff {
+		t.Errorf("got %s when off, want off", got)
+	}
+	if got := h.Action(HumidifierState{On: true, TargetHumidity: 50, HasTargetHumidity: true}); got != HumidifierActionIdle {
+		t.Errorf("got %s without a humidity, want idle", got)
+	}
+}
+
+func TestHumidifierController(t *testing.T) {
+	s := testSubscriber{}
+	p := &testPublisher{}
+	h := &Humidifier{
+		CommandTopic:               "dryer/set",
+		StateTopic:                 "dryer/state",
+		ActionTopic:                "dryer/action",
+		ModeCommandTopic:           "dryer/mode/set",
+		ModeStateTopic:             "dryer/mode",
+		Modes:                      []string{"eco", "sleep"},
+		TargetHumidityCommandTopic: "dryer/target/set",
+		TargetHumidityStateTopic:   "dryer/target",
+		CurrentHumidityTopic:       "dryer/current",
+		MinHumidity:                "30",
+		MaxHumidity:                "80",
+		PayloadResetHumidity:       "unknown",
+		DeviceClass:                HumidifierDeviceClassDehumidifier,
+	}
+	c, err := NewHumidifierController(h, p)
+	if err != nil {
+		t.Fatalf("could not create controller: %v", err)
+	}
+	var actions []HumidifierAction
+	c.OnAction = func(a HumidifierAction) { actions = append(actions, a) }
+	var errs []error
+	c.OnError = func(err error) { errs = append(errs, err) }
+	if err := c.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	last := func(topic string) string {
+		for i := len(*p) - 1; i >= 0; i-- {
+			if (*p)[i].topic == topic {
+				return (*p)[i].payload
+			}
+		}
+		return ""
+	}
+
+	if err := c.Publish(); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+	if last("dryer/state") != "OFF" || last("dryer/action") != "off" || last("dryer/mode") != "None" || last("dryer/target") != "unknown" || last("dryer/current") != "unknown" {
+		t.Errorf("published %v", *p)
+	}
+
+	s["dryer/set"](Message{Payload: []byte("ON")})
+	s["dryer/mode/set"](Message{Payload: []byte("eco")})
+	s["dryer/target/set"](Message{Payload: []byte("50")})
+	if err := c.SetCurrentHumidity(60); err != nil {
+		t.Fatalf("could not set the humidity: %v", err)
+	}
+	if last("dryer/action") != "drying" || last("dryer/current") != "60" || last("dryer/target") != "50" || last("dryer/mode") != "eco" {
+		t.Errorf("published %v", *p)
+	}
+	c.SetCurrentHumidity(46)
+	if last("dryer/action") != "idle" {
+		t.Errorf("action is %s, want idle", last("dryer/action"))
+	}
+
+	c.ResetCurrentHumidity()
+	if last("dryer/current") != "unknown" {
+		t.Errorf("current humidity is %s, want the reset payload", last("dryer/current"))
+	}
+
+	s["dryer/target/set"](Message{Payload: []byte("90")})
+	s["dryer/mode/set"](Message{Payload: []byte("boost")})
+	c.OnChange = func(HumidifierState) error { return errors.New("busy") }
+	s["dryer/set"](Message{Payload: []byte("OFF")})
+	if len(errs) != 3 {
+		t.Errorf("got errors %v, want 3", errs)
+	}
+	if !c.State().On {
+		t.Errorf("turned off when OnChange failed")
+	}
+
+	want := []HumidifierAction{HumidifierActionIdle, HumidifierActionDrying, HumidifierActionIdle}
+	if len(actions) != len(want) {
+		t.Fatalf("got actions %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("got actions %v, want %v", actions, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package discovery
+
+import "fmt"
+
+type Image struct {
+
+	// A list of MQTT topics subscribed to receive availability (online/offline) updates. Must not be used together with `availability_topic`
+	// Default: <no value>
+	Availability []Availability `json:"availability,omitempty"`
+
+	// When `availability` is configured, this controls the conditions needed to set the entity to `available`. Valid entries are `all`, `any`, and `latest`. If set to `all`, `payload_available` must be received on all configured availability topics before the entity is marked as online. If set to `any`, `payload_available` must be received on at least one configured availability topic before the entity is marked as online. If set to `latest`, the last `payload_available` or `payload_not_available` received on any configured availability topic controls the availability
+	// Default: latest
+	AvailabilityMode string `json:"availability_mode,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract device's availability from the `availability_topic`. To determine the devices's availability result of this template will be compared to `payload_available` and `payload_not_available`
+	// Default: <no value>
+	AvailabilityTemplate string `json:"availability_template,omitempty"`
+
+	// The MQTT topic subscribed to receive availability (online/offline) updates. Must not be used together with `availability`
+	// Default: <no value>
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	// The content type of and image data message received on `image_topic`. This option cannot be used with the `url_topic` because the content type is derived when downloading the image
+	// Default: image/png
+	ContentType string `json:"content_type,omitempty"`
+
+	// Information about the device this image is a part of to tie it into the [device registry](https://developers.home-assistant.io/docs/en/device_registry_index.html). Only works when [`unique_id`](#unique_id) is set. At least one of identifiers or connections must be present to identify the device
+	// Default: <no value>
+	Device *Device `json:"device,omitempty"`
+
+	// Flag which defines if the entity should be enabled when first added
+	// Default: true
+	EnabledByDefault bool `json:"enabled_by_default,omitempty"`
+
+	// The encoding of the payloads received. Set to `""` to disable decoding of incoming payload. Use `image_encoding` to enable `Base64` decoding on `image_topic`
+	// Default: utf-8
+	Encoding string `json:"encoding,omitempty"`
+
+	// The [category](https://developers.home-assistant.io/docs/core/entity#generic-properties) of the entity
+	// Default: <no value>
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	// Picture URL for the entity
+	// Default: <no value>
+	EntityPicture string `json:"entity_picture,omitempty"`
+
+	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
+	// Default: <no value>
+	Icon string `json:"icon,omitempty"`
+
+	// The encoding of the image payloads received. Set to `"b64"` to enable base64 decoding of image payload. If not set, the image payload must be raw binary data
+	// Default: <no value>
+	ImageEncoding string `json:"image_encoding,omitempty"`
+
+	// The MQTT topic to subscribe to receive the image payload of the image to be downloaded. Ensure the `content_type` type option is set to the corresponding content type. This option cannot be used together with the `url_topic` option. But at least one of these option is required
+	// Default: <no value>
+	ImageTopic string `json:"image_topic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the JSON dictionary from messages received on the `json_attributes_topic`
+	// Default: <no value>
+	JsonAttributesTemplate string `json:"json_attributes_template,omitempty"`
+
+	// The MQTT topic subscribed to receive a JSON dictionary payload and then set as sensor attributes. Implies `force_update` of the current sensor state when a message is received on this topic
+	// Default: <no value>
+	JsonAttributesTopic string `json:"json_attributes_topic,omitempty"`
+
+	// The name of the image. Can be set to `null` if only the device name is relevant
+	// Default: <no value>
+	Name string `json:"name,omitempty"`
+
+	// Used instead of `name` for automatic generation of `entity_id
+	// Default: <no value>
+	ObjectId string `json:"object_id,omitempty"`
+
+	// An ID that uniquely identifies this image. If two images have the same unique ID Home Assistant will raise an exception. Required when used with device-based discovery
+	// Default: <no value>
+	UniqueId string `json:"unique_id,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract an URL from the received URL topic payload
+	// Default: <no value>
+	UrlTemplate string `json:"url_template,omitempty"`
+
+	// The MQTT topic to subscribe to receive an image URL. A `url_template` option can extract the URL from the message. The `content_type` will be derived from the image when downloaded. This option cannot be used together with the `image_topic` option, but at least one of these options is required
+	// Default: <no value>
+	UrlTopic string `json:"url_topic,omitempty"`
+}
+
+// AnnounceTopic returns the topic to announce the discoverable Image
+// Topic has the format below:
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
+// 'object_id' is either the UniqueId, the Name, or a hash of the Image
+func (d *Image) AnnounceTopic(prefix string) string {
+	topicFormat := "%s/image/%s/config"
+	objectID := ""
+	switch {
+	case d.UniqueId != "":
+		objectID = d.UniqueId
+	case d.Name != "":
+		objectID = d.Name
+	default:
+		objectID = hash(d)
+	}
+
+	return fmt.Sprintf(topicFormat, prefix, objectID)
+}
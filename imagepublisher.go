@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Content types the ImagePublisher can encode images in.
+const (
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+)
+
+// ImagePublisher publishes the images of an Image to Home Assistant, either as image data on
+// the ImageTopic, or as a URL on the UrlTopic.
+type ImagePublisher struct {
+	Image     *Image
+	Publisher Publisher
+	// Retain the published image, so that Home Assistant has it after it restarts.
+	Retain bool
+	// JPEGOptions are used to encode JPEG images. The default quality is used if it is nil.
+	JPEGOptions *jpeg.Options
+}
+
+// NewImagePublisher creates an ImagePublisher that publishes the images of i with p.
+func NewImagePublisher(i *Image, p Publisher) *ImagePublisher {
+	return &ImagePublisher{
+		Image:     i,
+		Publisher: p,
+	}
+}
+
+// ContentType returns the content type of the images published, which defaults to PNG as in
+// Home Assistant.
+func (ip *ImagePublisher) ContentType() string {
+	return orDefault(ip.Image.ContentType, ContentTypePNG)
+}
+
+// PublishImage encodes img in the Image's content type, which must be PNG or JPEG, and
+// publishes it.
+func (ip *ImagePublisher) PublishImage(img image.Image) error {
+	var buf bytes.Buffer
+	var err error
+	switch ct := ip.ContentType(); ct {
+	case ContentTypePNG:
+		err = png.Encode(&buf, img)
+	case ContentTypeJPEG:
+		err = jpeg.Encode(&buf, img, ip.JPEGOptions)
+	default:
+		return fmt.Errorf("can not encode images as %q", ct)
+	}
+	if err != nil {
+		return fmt.Errorf("could not encode image: %v", err)
+	}
+	return ip.publish(buf.Bytes())
+}
+
+// PublishReader publishes the image data read from r as it is. The data must already be in
+// the Image's content type.
+func (ip *ImagePublisher) PublishReader(r io.Reader) error {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read image: %v", err)
+	}
+	if ct := http.DetectContentType(bs); ct != ip.ContentType() {
+		return fmt.Errorf("image is %q, not %q", ct, ip.ContentType())
+	}
+	return ip.publish(bs)
+}
+
+// PublishURL publishes the URL Home Assistant should download the image from.
+func (ip *ImagePublisher) PublishURL(url string) error {
+	if ip.Image.UrlTopic == "" {
+		return fmt.Errorf("image has no url topic")
+	}
+	err := ip.Publisher.Publish(ip.Image.UrlTopic, 0, ip.Retain, []byte(url))
+	if err != nil {
+		return fmt.Errorf("could not publish image url: %v", err)
+	}
+	return nil
+}
+
+func (ip *ImagePublisher) publish(bs []byte) error {
+	if ip.Image.ImageTopic == "" {
+		return fmt.Errorf("image has no image topic")
+	}
+	if ip.Image.ImageEncoding == "b64" {
+		bs = encodeBase64(bs)
+	}
+	err := ip.Publisher.Publish(ip.Image.ImageTopic, 0, ip.Retain, bs)
+	if err != nil {
+		return fmt.Errorf("could not publish image: %v", err)
+	}
+	return nil
+}
+
+func encodeBase64(bs []byte) []byte {
+	b := make([]byte, base64.StdEncoding.EncodedLen(len(bs)))
+	base64.StdEncoding.Encode(b, bs)
+	return b
+}
@@ -0,0 +1,68 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+func TestImagePublisher(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.White)
+
+	p := &testPublisher{}
+	i := &Image{ImageTopic: "garden/snapshot", UrlTopic: "garden/url"}
+	ip := NewImagePublisher(i, p)
+
+	if err := ip.PublishImage(img); err != nil {
+		t.Fatalf("could not publish image: %v", err)
+	}
+	if ct := http.DetectContentType([]byte((*p)[0].payload)); ct != ContentTypePNG {
+		t.Errorf("published %q, want png", ct)
+	}
+
+	i.ContentType = ContentTypeJPEG
+	i.ImageEncoding = "b64"
+	if err := ip.PublishImage(img); err != nil {
+		t.Fatalf("could not publish image: %v", err)
+	}
+	bs, err := base64.StdEncoding.DecodeString((*p)[1].payload)
+	if err != nil {
+		t.Fatalf("image is not base64 encoded: %v", err)
+	}
+	if ct := http.DetectContentType(bs); ct != ContentTypeJPEG {
+		t.Errorf("published %q, want jpeg", ct)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := ip.PublishReader(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Errorf("expected an error publishing a png as a jpeg")
+	}
+	i.ContentType = ""
+	i.ImageEncoding = ""
+	if err := ip.PublishReader(&buf); err != nil {
+		t.Errorf("could not publish reader: %v", err)
+	}
+
+	i.ContentType = "image/gif"
+	if err := ip.PublishImage(img); err == nil {
+		t.Errorf("expected an error encoding a gif")
+	}
+
+	if err := ip.PublishURL("http://example.com/garden.png"); err != nil {
+		t.Fatalf("could not publish url: %v", err)
+	}
+	if m := (*p)[len(*p)-1]; m.topic != "garden/url" || m.payload != "http://example.com/garden.png" {
+		t.Errorf("published %+v", m)
+	}
+	if len(*p) != 4 {
+		t.Errorf("got %d messages, want 4", len(*p))
+	}
+}
@@ -0,0 +1,131 @@
+package discovery
+
+import "fmt"
+
+type LawnMower struct {
+
+	// The MQTT topic subscribed to receive an update of the activity. Valid activities are `mowing`, `paused`, `docked`, `returning` and `error`. Use `value_template` to extract the activity state from a custom payload. When payload `none` is received, the activity state will be reset to `unknown`
+	// Default: <no value>
+	ActivityStateTopic string `json:"activity_state_topic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the value
+	// Default: <no value>
+	ActivityValueTemplate string `json:"activity_value_template,omitempty"`
+
+	// A list of MQTT topics subscribed to receive availability (online/offline) updates. Must not be used together with `availability_topic`
+	// Default: <no value>
+	Availability []Availability `json:"availability,omitempty"`
+
+	// When `availability` is configured, this controls the conditions needed to set the entity to `available`. Valid entries are `all`, `any`, and `latest`. If set to `all`, `payload_available` must be received on all configured availability topics before the entity is marked as online. If set to `any`, `payload_available` must be received on at least one configured availability topic before the entity is marked as online. If set to `latest`, the last `payload_available` or `payload_not_available` received on any configured availability topic controls the availability
+	// Default: latest
+	AvailabilityMode string `json:"availability_mode,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract device's availability from the `availability_topic`. To determine the devices's availability result of this template will be compared to `payload_available` and `payload_not_available`
+	// Default: <no value>
+	AvailabilityTemplate string `json:"availability_template,omitempty"`
+
+	// The MQTT topic subscribed to receive availability (online/offline) updates. Must not be used together with `availability`
+	// Default: <no value>
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	// Information about the device this lawn mower is a part of to tie it into the [device registry](https://developers.home-assistant.io/docs/en/device_registry_index.html). Only works when [`unique_id`](#unique_id) is set. At least one of identifiers or connections must be present to identify the device
+	// Default: <no value>
+	Device *Device `json:"device,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `dock_command_topic`. The `value` parameter in the template will be set to `dock`
+	// Default: <no value>
+	DockCommandTemplate string `json:"dock_command_template,omitempty"`
+
+	// The MQTT topic that publishes commands when the `lawn_mower.dock` action is performed. The value `dock` is published when the action is used. Use a `dock_command_template` to publish a custom format
+	// Default: <no value>
+	DockCommandTopic string `json:"dock_command_topic,omitempty"`
+
+	// Flag which defines if the entity should be enabled when first added
+	// Default: true
+	EnabledByDefault bool `json:"enabled_by_default,omitempty"`
+
+	// The encoding of the payloads received and published messages. Set to `""` to disable decoding of incoming payload
+	// Default: utf-8
+	Encoding string `json:"encoding,omitempty"`
+
+	// The [category](https://developers.home-assistant.io/docs/core/entity#generic-properties) of the entity
+	// Default: <no value>
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	// Picture URL for the entity
+	// Default: <no value>
+	EntityPicture string `json:"entity_picture,omitempty"`
+
+	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
+	// Default: <no value>
+	Icon string `json:"icon,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the JSON dictionary from messages received on the `json_attributes_topic`. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-template-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTemplate string `json:"json_attributes_template,omitempty"`
+
+	// The MQTT topic subscribed to receive a JSON dictionary payload and then set as sensor attributes. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-topic-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTopic string `json:"json_attributes_topic,omitempty"`
+
+	// The name of the lawn mower. Can be set to `null` if only the device name is relevant
+	// Default: <no value>
+	Name string `json:"name,omitempty"`
+
+	// Used instead of `name` for automatic generation of `entity_id
+	// Default: <no value>
+	ObjectId string `json:"object_id,omitempty"`
+
+	// Flag that defines if lawn mower works in optimistic mode
+	// Default: `true` if no `state_topic` defined, else `false`.
+	Optimistic bool `json:"optimistic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `pause_command_topic`. The `value` parameter in the template will be set to `pause`
+	// Default: <no value>
+	PauseCommandTemplate string `json:"pause_command_template,omitempty"`
+
+	// The MQTT topic that publishes commands when the `lawn_mower.pause` action is performed. The value `pause` is published when the action is used. Use a `pause_command_template` to publish a custom format
+	// Default: <no value>
+	PauseCommandTopic string `json:"pause_command_topic,omitempty"`
+
+	// The maximum QoS level to be used when receiving and publishing messages
+	// Default: 0
+	Qos int `json:"qos,omitempty"`
+
+	// If the published message should have the retain flag on or not
+	// Default: false
+	Retain bool `json:"retain,omitempty"`
+
+	// The MQTT topic that publishes commands when the `lawn_mower.start_mowing` action is performed. The value `start_mowing` is published when the action is used. Use a `start_mowing_template` to publish a custom format
+	// Default: <no value>
+	StartMowingCommandTopic string `json:"start_mowing_command_topic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `start_mowing_command_topic`. The `value` parameter in the template will be set to `start_mowing`
+	// Default: <no value>
+	StartMowingTemplate string `json:"start_mowing_template,omitempty"`
+
+	// An ID that uniquely identifies this lawn mower device. If two lawn mowers have the same unique ID, Home Assistant will raise an exception. Required when used with device-based discovery
+	// Default: <no value>
+	UniqueId string `json:"unique_id,omitempty"`
+}
+
+// AnnounceTopic returns the topic to announce the discoverable LawnMower
+// Topic has the format below:
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
+// 'object_id' is either the UniqueId, the Name, or a hash of the LawnMower
+func (d *LawnMower) AnnounceTopic(prefix string) string {
+	topicFormat := "%s/lawn_mower/%s/config"
+	objectID := ""
+	switch {
+	case d.UniqueId != "":
+		objectID = d.UniqueId
+	case d.Name != "":
+		objectID = d.Name
+	default:
+		objectID = hash(d)
+	}
+
+	return fmt.Sprintf(topicFormat, prefix, objectID)
+}
@@ -0,0 +1,191 @@
+package discovery
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LawnMowerCommand is a command Home Assistant sends to a LawnMower. The commands are sent
+// to their own topics, and are the payload unless a command template is configured.
+type LawnMowerCommand string
+
+const (
+	LawnMowerCommandStartMowing LawnMowerCommand = "start_mowing"
+	LawnMowerCommandPause       LawnMowerCommand = "pause"
+	LawnMowerCommandDock        LawnMowerCommand = "dock"
+)
+
+// lawnMowerTransitions are the activities a lawn mower can move to from each activity. A
+// mower can't start mowing straight out of an error, it has to be paused or docked first.
+var lawnMowerTransitions = map[LawnMowerActivity][]LawnMowerActivity{
+	LawnMowerActivityDocked:    {LawnMowerActivityMowing, LawnMowerActivityError},
+	LawnMowerActivityMowing:    {LawnMowerActivityPaused, LawnMowerActivityReturning, LawnMowerActivityDocked, LawnMowerActivityError},
+	LawnMowerActivityPaused:    {LawnMowerActivityMowing, LawnMowerActivityReturning, LawnMowerActivityDocked, LawnMowerActivityError},
+	LawnMowerActivityReturning: {LawnMowerActivityDocked, LawnMowerActivityMowing, LawnMowerActivityPaused, LawnMowerActivityError},
+	LawnMowerActivityError:     {LawnMowerActivityDocked, LawnMowerActivityPaused, LawnMowerActivityReturning},
+}
+
+// LawnMowerMachine tracks the activity of a LawnMower, and publishes it to the
+// ActivityStateTopic. Activities change with the commands from Home Assistant, or with
+// SetActivity when the mower reports them, and only along the transitions a mower can make.
+type LawnMowerMachine struct {
+	LawnMower *LawnMower
+	Publisher Publisher
+
+	// OnCommand is called with the commands from Home Assistant. The activity only changes if
+	// it succeeds.
+	OnCommand func(LawnMowerCommand) error
+	// OnError is called with the errors from handling commands.
+	OnError func(error)
+
+	mu       sync.Mutex
+	activity LawnMowerActivity
+}
+
+// NewLawnMowerMachine creates a LawnMowerMachine for l that publishes with p. The activity is
+// unknown until it is first set.
+func NewLawnMowerMachine(l *LawnMower, p Publisher) *LawnMowerMachine {
+	return &LawnMowerMachine{
+		LawnMower: l,
+		Publisher: p,
+	}
+}
+
+// Activity returns the current activity, or "" if it is not known yet.
+func (lm *LawnMowerMachine) Activity() LawnMowerActivity {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.activity
+}
+
+// CanTransition returns whether a mower can move from activity from to activity to. Any
+// activity can follow an unknown one, and staying in the same activity is always allowed.
+func (from LawnMowerActivity) CanTransition(to LawnMowerActivity) bool {
+	if _, ok := lawnMowerTransitions[to]; !ok {
+		return false
+	}
+	if from == "" || from == to {
+		return true
+	}
+	for _, a := range lawnMowerTransitions[from] {
+		if a == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SetActivity moves the mower to activity a, and publishes it.
+func (lm *LawnMowerMachine) SetActivity(a LawnMowerActivity) error {
+	lm.mu.Lock()
+	if !lm.activity.CanTransition(a) {
+		from := lm.activity
+		lm.mu.Unlock()
+		return fmt.Errorf("lawn mower can not go from %q to %q", from, a)
+	}
+	lm.activity = a
+	lm.mu.Unlock()
+
+	return lm.publish(a)
+}
+
+func (lm *LawnMowerMachine) publish(a LawnMowerActivity) error {
+	if lm.LawnMower.ActivityStateTopic == "" {
+		return nil
+	}
+	err := lm.Publisher.Publish(lm.LawnMower.ActivityStateTopic, byte(lm.LawnMower.Qos), lm.LawnMower.Retain, []byte(a))
+	if err != nil {
+		return fmt.Errorf("could not publish activity: %v", err)
+	}
+	return nil
+}
+
+// target returns the activity a command moves the mower to from activity a.
+func (c LawnMowerCommand) target(a LawnMowerActivity) LawnMowerActivity {
+	switch c {
+	case LawnMowerCommandStartMowing:
+		return LawnMowerActivityMowing
+	case LawnMowerCommandPause:
+		return LawnMowerActivityPaused
+	case LawnMowerCommandDock:
+		if a == LawnMowerActivityDocked {
+			return a
+		}
+		return LawnMowerActivityReturning
+	}
+	return ""
+}
+
+// Command checks that command c is possible in the current activity, passes it to OnCommand,
+// and moves the mower to the activity the command starts. Docking moves the mower to
+// returning, until it reports that it is docked.
+func (lm *LawnMowerMachine) Command(c LawnMowerCommand) error {
+	lm.mu.Lock()
+	from := lm.activity
+	to := c.target(from)
+	lm.mu.Unlock()
+	if to == "" {
+		return fmt.Errorf("unknown lawn mower command %q", c)
+	}
+	if !from.CanTransition(to) {
+		return fmt.Errorf("lawn mower can not %s when %s", c, from)
+	}
+
+	if lm.OnCommand != nil {
+		if err := lm.OnCommand(c); err != nil {
+			return err
+		}
+	}
+
+	lm.mu.Lock()
+	if lm.activity != from && !lm.activity.CanTransition(to) {
+		now := lm.activity
+		lm.mu.Unlock()
+		return fmt.Errorf("lawn mower can not %s when %s", c, now)
+	}
+	lm.activity = to
+	lm.mu.Unlock()
+
+	return lm.publish(to)
+}
+
+// Subscribe subscribes the machine to the LawnMower's command topics.
+func (lm *LawnMowerMachine) Subscribe(s Subscriber) error {
+	for _, sub := range []struct {
+		topic    string
+		template string
+		command  LawnMowerCommand
+	}{
+		{lm.LawnMower.StartMowingCommandTopic, lm.LawnMower.StartMowingTemplate, LawnMowerCommandStartMowing},
+		{lm.LawnMower.PauseCommandTopic, lm.LawnMower.PauseCommandTemplate, LawnMowerCommandPause},
+		{lm.LawnMower.DockCommandTopic, lm.LawnMower.DockCommandTemplate, LawnMowerCommandDock},
+	} {
+		if sub.topic == "" {
+			continue
+		}
+		sub := sub
+		err := s.Subscribe(sub.topic, byte(lm.LawnMower.Qos), func(m Message) {
+			err := lm.handle(m, sub.template, sub.command)
+			if err != nil && lm.OnError != nil {
+				lm.OnError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", sub.topic, err)
+		}
+	}
+	return nil
+}
+
+// handle handles a message on the topic of command c. The payload is only checked if there
+// is no template, since the topic identifies the command. Retained messages are ignored, so
+// that an old command doesn't move the mower again on every reconnect.
+func (lm *LawnMowerMachine) handle(m Message, template string, c LawnMowerCommand) error {
+	if m.Retained {
+		return nil
+	}
+	if template == "" && string(m.Payload) != string(c) {
+		return fmt.Errorf("unexpected payload %q for %s", m.Payload, c)
+	}
+	return lm.Command(c)
+}
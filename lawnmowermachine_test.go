@@ -0,0 +1,96 @@
+package discovery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLawnMowerMachine(t *testing.T) {
+	s := testSubscriber{}
+	p := &testPublisher{}
+	l := &LawnMower{
+		ActivityStateTopic:      "mower/activity",
+		StartMowingCommandTopic: "mower/start",
+		PauseCommandTopic:       "mower/pause",
+		DockCommandTopic:        "mower/dock",
+		DockCommandTemplate:     `{"cmd": "{{ value }}"}`,
+	}
+	lm := NewLawnMowerMachine(l, p)
+	var commands []LawnMowerCommand
+	lm.OnCommand = func(c LawnMowerCommand) error {
+		commands = append(commands, c)
+		return nil
+	}
+	var errs []error
+	lm.OnError = func(err error) { errs = append(errs, err) }
+	if err := lm.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	if err := lm.SetActivity(LawnMowerActivityDocked); err != nil {
+		t.Fatalf("could not set activity: %v", err)
+	}
+
+	s["mower/pause"](Message{Payload: []byte("pause")})
+	if len(errs) != 1 || lm.Activity() != LawnMowerActivityDocked {
+		t.Errorf("paused a docked mower: %v", errs)
+	}
+
+	s["mower/start"](Message{Payload: []byte("start_mowing")})
+	s["mower/dock"](Message{Payload: []byte(`{"cmd": "dock"}`)})
+	if lm.Activity() != LawnMowerActivityReturning {
+		t.Errorf("activity is %q, want returning", lm.Activity())
+	}
+	if err := lm.SetActivity(LawnMowerActivityDocked); err != nil {
+		t.Errorf("could not dock: %v", err)
+	}
+
+	s["mower/start"](Message{Payload: []byte("start_mowing"), Retained: true})
+	if lm.Activity() != LawnMowerActivityDocked {
+		t.Errorf("a retained command started the mower")
+	}
+
+	s["mower/start"](Message{Payload: []byte("go")})
+	if len(errs) != 2 {
+		t.Errorf("accepted an unexpected payload: %v", errs)
+	}
+
+	want := []string{"docked", "mowing", "returning", "docked"}
+	if len(*p) != len(want) {
+		t.Fatalf("published %+v, want %v", *p, want)
+	}
+	for i, w := range want {
+		if m := (*p)[i]; m.topic != "mower/activity" || m.payload != w {
+			t.Errorf("published %+v, want %s", m, w)
+		}
+	}
+	if len(commands) != 2 || commands[0] != LawnMowerCommandStartMowing || commands[1] != LawnMowerCommandDock {
+		t.Errorf("got commands %v", commands)
+	}
+
+	lm.SetActivity(LawnMowerActivityError)
+	if err := lm.SetActivity(LawnMowerActivityMowing); err == nil {
+		t.Errorf("expected an error mowing straight out of an error")
+	}
+	lm.OnCommand = func(LawnMowerCommand) error { return errors.New("stuck") }
+	if err := lm.Command(LawnMowerCommandPause); err == nil || lm.Activity() != LawnMowerActivityError {
+		t.Errorf("activity changed when the command failed")
+	}
+}
+
+func TestLawnMowerActivityCanTransition(t *testing.T) {
+	for _, tc := range []struct {
+		from, to LawnMowerActivity
+		want     bool
+	}{
+		{"", LawnMowerActivityMowing, true},
+		{LawnMowerActivityMowing, LawnMowerActivityMowing, true},
+		{LawnMowerActivityDocked, LawnMowerActivityPaused, false},
+		{LawnMowerActivityReturning, LawnMowerActivityDocked, true},
+		{LawnMowerActivityMowing, "flying", false},
+	} {
+		if got := tc.from.CanTransition(tc.to); got != tc.want {
+			t.Errorf("%q.CanTransition(%q) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
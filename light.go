@@ -279,7 +279,9 @@ type Light struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Light
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Light
 func (d *Light) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/light/%s/config"
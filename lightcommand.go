@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/duncanvanzyl/hass-discovery/color"
+)
+
+// Default colour temperature range of a Light, in mireds.
+const (
+	DefaultMinMireds = 153
+	DefaultMaxMireds = 500
+)
+
+// LightCommand is a command sent to one of the command topics of a Light. Only the fields for
+// the topic the command was sent to are set.
+//
+// RGB is set for every colour command, converted from the colour it was sent as, so that a
+// light with only red, green and blue channels can handle them all. Colour temperatures are
+// converted to the colour of white light at that temperature.
+type LightCommand struct {
+	State *bool
+	// Brightness is scaled from the BrightnessScale to between 0 and 255.
+	Brightness *int
+	// ColorTemp is in mireds.
+	ColorTemp *int
+	Effect    string
+	HS        *color.HS
+	RGB       *color.RGB
+	RGBW      *color.RGBW
+	RGBWW     *color.RGBWW
+	XY        *color.XY
+	// White is scaled from the WhiteScale to between 0 and 255.
+	White *int
+}
+
+// KelvinRange returns the colour temperature range of the light in kelvin.
+func (l *Light) KelvinRange() (min, max int) {
+	minMireds := l.MinMireds
+	if minMireds == 0 {
+		minMireds = DefaultMinMireds
+	}
+	maxMireds := l.MaxMireds
+	if maxMireds == 0 {
+		maxMireds = DefaultMaxMireds
+	}
+	return color.MiredToKelvin(maxMireds), color.MiredToKelvin(minMireds)
+}
+
+// DecodeCommand decodes a command sent to topic, which must be one of the Light's command
+// topics. Commands on topics with a command template can't be decoded, since their format is
+// not known.
+func (l *Light) DecodeCommand(topic string, payload []byte) (LightCommand, error) {
+	var c LightCommand
+	p := strings.TrimSpace(string(payload))
+
+	decode := func(template string, n int) ([]float64, error) {
+		if template != "" {
+			return nil, fmt.Errorf("can not decode commands on %s with a command template", topic)
+		}
+		return parseNumbers(p, n)
+	}
+
+	switch topic {
+	case "":
+		return c, fmt.Errorf("no topic")
+
+	case l.CommandTopic:
+		var on bool
+		switch p {
+		case orDefault(l.PayloadOn, "ON"):
+			on = true
+		case orDefault(l.PayloadOff, "OFF"):
+		default:
+			return c, fmt.Errorf("unknown light state %q", p)
+		}
+		c.State = &on
+
+	case l.BrightnessCommandTopic:
+		v, err := decode(l.BrightnessCommandTemplate, 1)
+		if err != nil {
+			return c, err
+		}
+		b := color.ScaleBrightness(int(v[0]), scale(l.BrightnessScale), 255)
+		c.Brightness = &b
+
+	case l.WhiteCommandTopic:
+		v, err := parseNumbers(p, 1)
+		if err != nil {
+			return c, err
+		}
+		w := color.ScaleBrightness(int(v[0]), scale(l.WhiteScale), 255)
+		c.White = &w
+
+	case l.ColorTempCommandTopic:
+		v, err := decode(l.ColorTempCommandTemplate, 1)
+		if err != nil {
+			return c, err
+		}
+		mireds := int(v[0])
+		c.ColorTemp = &mireds
+		rgb := color.TemperatureToRGB(color.MiredToKelvin(mireds))
+		c.RGB = &rgb
+
+	case l.EffectCommandTopic:
+		if l.EffectCommandTemplate != "" {
+			return c, fmt.Errorf("can not decode commands on %s with a command template", topic)
+		}
+		c.Effect = p
+
+	case l.HsCommandTopic:
+		v, err := decode(l.HsCommandTemplate, 2)
+		if err != nil {
+			return c, err
+		}
+		c.HS = &color.HS{H: v[0], S: v[1]}
+		rgb := color.HSToRGB(*c.HS)
+		c.RGB = &rgb
+
+	case l.RgbCommandTopic:
+		v, err := decode(l.RgbCommandTemplate, 3)
+		if err != nil {
+			return c, err
+		}
+		ch, err := channels(v)
+		if err != nil {
+			return c, err
+		}
+		c.RGB = &color.RGB{R: ch[0], G: ch[1], B: ch[2]}
+
+	case l.RgbwCommandTopic:
+		v, err := decode(l.RgbwCommandTemplate, 4)
+		if err != nil {
+			return c, err
+		}
+		ch, err := channels(v)
+		if err != nil {
+			return c, err
+		}
+		c.RGBW = &color.RGBW{R: ch[0], G: ch[1], B: ch[2], W: ch[3]}
+		rgb := color.RGBWToRGB(*c.RGBW)
+		c.RGB = &rgb
+
+	case l.RgbwwCommandTopic:
+		v, err := decode(l.RgbwwCommandTemplate, 5)
+		if err != nil {
+			return c, err
+		}
+		ch, err := channels(v)
+		if err != nil {
+			return c, err
+		}
+		c.RGBWW = &color.RGBWW{R: ch[0], G: ch[1], B: ch[2], CW: ch[3], WW: ch[4]}
+		min, max := l.KelvinRange()
+		rgb := color.RGBWWToRGB(*c.RGBWW, min, max)
+		c.RGB = &rgb
+
+	case l.XyCommandTopic:
+		v, err := decode(l.XyCommandTemplate, 2)
+		if err != nil {
+			return c, err
+		}
+		c.XY = &color.XY{X: v[0], Y: v[1]}
+		rgb := color.XYToRGB(*c.XY, 255, nil)
+		c.RGB = &rgb
+
+	default:
+		return c, fmt.Errorf("%s is not a command topic of the light", topic)
+	}
+
+	return c, nil
+}
+
+// scale returns a brightness or white scale, which defaults to 255.
+func scale(s int) int {
+	if s == 0 {
+		return 255
+	}
+	return s
+}
+
+// parseNumbers parses the n comma separated numbers in s.
+func parseNumbers(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d values, got %q", n, s)
+	}
+	v := make([]float64, n)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q: %v", s, err)
+		}
+		v[i] = f
+	}
+	return v, nil
+}
+
+// channels converts values to colour channels, checking that they are between 0 and 255.
+func channels(v []float64) ([]uint8, error) {
+	ch := make([]uint8, len(v))
+	for i, f := range v {
+		if f < 0 || f > 255 {
+			return nil, fmt.Errorf("channel value %v is outside 0 to 255", f)
+		}
+		ch[i] = uint8(f)
+	}
+	return ch, nil
+}
@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/duncanvanzyl/hass-discovery/color"
+)
+
+func TestLightDecodeCommand(t *testing.T) {
+	l := &Light{
+		CommandTopic:           "lamp/set",
+		BrightnessCommandTopic: "lamp/brightness/set",
+		BrightnessScale:        100,
+		HsCommandTopic:         "lamp/hs/set",
+		XyCommandTopic:         "lamp/xy/set",
+		RgbwCommandTopic:       "lamp/rgbw/set",
+		RgbwwCommandTopic:      "lamp/rgbww/set",
+		MinMireds:              154,
+		MaxMireds:              370,
+		ColorTempCommandTopic:  "lamp/ct/set",
+		EffectCommandTopic:     "lamp/effect/set",
+		RgbCommandTopic:        "lamp/rgb/set",
+		RgbCommandTemplate:     "{{ red }}-{{ green }}-{{ blue }}",
+	}
+
+	c, err := l.DecodeCommand("lamp/set", []byte("ON"))
+	if err != nil || c.State == nil || !*c.State {
+		t.Errorf("could not decode ON: %+v, %v", c, err)
+	}
+
+	c, err = l.DecodeCommand("lamp/brightness/set", []byte("50"))
+	if err != nil || c.Brightness == nil || *c.Brightness != 128 {
+		t.Errorf("could not decode brightness: %+v, %v", c, err)
+	}
+
+	for _, tc := range []struct {
+		topic, payload string
+		rgb            color.RGB
+	}{
+		{"lamp/hs/set", "240.0,100.0", color.RGB{R: 0, G: 0, B: 255}},
+		{"lamp/xy/set", "0.35,0.35", color.RGB{R: 255, G: 243, B: 222}},
+		{"lamp/rgbw/set", "255,127,0,0", color.RGB{R: 255, G: 127, B: 0}},
+		{"lamp/rgbww/set", "0,54,98,255,255", color.RGB{R: 255, G: 255, B: 255}},
+		{"lamp/ct/set", "200", color.TemperatureToRGB(5000)},
+	} {
+		c, err := l.DecodeCommand(tc.topic, []byte(tc.payload))
+		if err != nil {
+			t.Errorf("could not decode %s on %s: %v", tc.payload, tc.topic, err)
+			continue
+		}
+		if c.RGB == nil || *c.RGB != tc.rgb {
+			t.Errorf("%s on %s: got rgb %v, want %v", tc.payload, tc.topic, c.RGB, tc.rgb)
+		}
+	}
+
+	c, err = l.DecodeCommand("lamp/effect/set", []byte("rainbow"))
+	if err != nil || c.Effect != "rainbow" {
+		t.Errorf("could not decode effect: %+v, %v", c, err)
+	}
+
+	for _, tc := range []struct{ topic, payload string }{
+		{"lamp/set", "TOGGLE"},
+		{"lamp/hs/set", "240"},
+		{"lamp/rgbw/set", "256,0,0,0"},
+		{"lamp/rgb/set", "255-0-0"},
+		{"lamp/other", "ON"},
+	} {
+		if _, err := l.DecodeCommand(tc.topic, []byte(tc.payload)); err == nil {
+			t.Errorf("expected an error decoding %s on %s", tc.payload, tc.topic)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ZoneHome is the name of the home zone, which is published as the PayloadHome.
+const ZoneHome = "home"
+
+// earthRadius is the mean radius of the earth in metres.
+const earthRadius = 6371008.8
+
+// Location is a GPS position, with its accuracy in metres.
+type Location struct {
+	Latitude    float64
+	Longitude   float64
+	GPSAccuracy float64
+}
+
+// Distance returns the distance in metres between l and the given coordinates.
+func (l Location) Distance(latitude, longitude float64) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Zone is a circular geofence. Radius is in metres.
+type Zone struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+}
+
+// LocationPublisher publishes the location of a DeviceTracker as the latitude, longitude and
+// gps_accuracy attributes on its JsonAttributesTopic.
+//
+// If Zones are configured, it also works out the zone from the location and publishes it to
+// the StateTopic, as the PayloadHome in the ZoneHome zone, the PayloadNotHome outside all
+// zones, and the zone name otherwise. A location enters a zone once it is within the zone's
+// radius, and only leaves it once it is further than Hysteresis outside the radius, so that
+// a location near the edge doesn't flap between zones.
+type LocationPublisher struct {
+	DeviceTracker *DeviceTracker
+	Publisher     Publisher
+	Zones         []Zone
+	// Hysteresis is how far in metres a location must be outside a zone to leave it.
+	Hysteresis float64
+
+	mu        sync.Mutex
+	zone      string
+	published bool
+}
+
+// NewLocationPublisher creates a LocationPublisher that publishes the location of d with p.
+func NewLocationPublisher(d *DeviceTracker, p Publisher) *LocationPublisher {
+	return &LocationPublisher{
+		DeviceTracker: d,
+		Publisher:     p,
+	}
+}
+
+// Publish publishes the location, along with the extra attributes, as
+//
+//	{"latitude": ..., "longitude": ..., "gps_accuracy": ..., <attrs>...}
+//
+// and the zone it is in, if that changed.
+func (lp *LocationPublisher) Publish(l Location, attrs map[string]interface{}) error {
+	payload := make(map[string]interface{}, len(attrs)+3)
+	for k, v := range attrs {
+		payload[k] = v
+	}
+	payload["latitude"] = l.Latitude
+	payload["longitude"] = l.Longitude
+	payload["gps_accuracy"] = l.GPSAccuracy
+
+	bs, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal location: %v", err)
+	}
+	if err := lp.publish(lp.DeviceTracker.JsonAttributesTopic, bs); err != nil {
+		return err
+	}
+
+	if len(lp.Zones) == 0 {
+		return nil
+	}
+
+	lp.mu.Lock()
+	zone := lp.locate(l)
+	changed := zone != lp.zone || !lp.published
+	lp.zone = zone
+	lp.published = true
+	lp.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return lp.publish(lp.DeviceTracker.StateTopic, []byte(lp.state(zone)))
+}
+
+// Zone returns the name of the zone of the last location, or an empty string if it is
+// outside all zones.
+func (lp *LocationPublisher) Zone() string {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return lp.zone
+}
+
+// Reset publishes the PayloadReset to the StateTopic, so that Home Assistant works out the
+// zone from the location itself. The zone is published again with the next location.
+func (lp *LocationPublisher) Reset() error {
+	lp.mu.Lock()
+	lp.zone = ""
+	lp.published = false
+	lp.mu.Unlock()
+
+	return lp.publish(lp.DeviceTracker.StateTopic, []byte(orDefault(lp.DeviceTracker.PayloadReset, DefaultPayloadReset)))
+}
+
+// locate returns the zone l is in. It stays in the current zone while it is within the
+// Hysteresis of it, and otherwise enters the nearest zone it is inside.
+func (lp *LocationPublisher) locate(l Location) string {
+	nearest, best := "", math.Inf(1)
+	for _, z := range lp.Zones {
+		d := l.Distance(z.Latitude, z.Longitude)
+		if lp.published && z.Name == lp.zone && d <= z.Radius+lp.Hysteresis {
+			return z.Name
+		}
+		if d <= z.Radius && d < best {
+			nearest, best = z.Name, d
+		}
+	}
+	return nearest
+}
+
+func (lp *LocationPublisher) state(zone string) string {
+	switch zone {
+	case "":
+		return orDefault(lp.DeviceTracker.PayloadNotHome, "not_home")
+	case ZoneHome:
+		return orDefault(lp.DeviceTracker.PayloadHome, "home")
+	}
+	return zone
+}
+
+func (lp *LocationPublisher) publish(topic string, payload []byte) error {
+	if topic == "" {
+		return nil
+	}
+	err := lp.Publisher.Publish(topic, byte(lp.DeviceTracker.Qos), false, payload)
+	if err != nil {
+		return fmt.Errorf("could not publish to %s: %v", topic, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLocationDistance(t *testing.T) {
+	l := Location{Latitude: 0, Longitude: 0}
+	if d := l.Distance(1, 0); math.Abs(d-111195) > 1 {
+		t.Errorf("got %v, want about 111195", d)
+	}
+	l = Location{Latitude: 51.5007, Longitude: -0.1246}
+	if d := l.Distance(40.6892, -74.0445); math.Abs(d-5574840) > 1000 {
+		t.Errorf("got %v, want about 5574840", d)
+	}
+}
+
+func TestLocationPublisher(t *testing.T) {
+	p := &testPublisher{}
+	d := &DeviceTracker{
+		StateTopic:          "phone/state",
+		JsonAttributesTopic: "phone/attributes",
+		PayloadNotHome:      "away",
+	}
+	lp := NewLocationPublisher(d, p)
+
+	if err := lp.Publish(Location{Latitude: 1, Longitude: 2, GPSAccuracy: 5}, map[string]interface{}{"battery": 80}); err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+	if len(*p) != 1 {
+		t.Fatalf("got %d messages, want 1 without zones: %+v", len(*p), *p)
+	}
+	got := map[string]interface{}{}
+	if err := json.Unmarshal([]byte((*p)[0].payload), &got); err != nil {
+		t.Fatalf("attributes are not json: %v", err)
+	}
+	want := map[string]interface{}{"latitude": float64(1), "longitude": float64(2), "gps_accuracy": float64(5), "battery": float64(80)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got attributes %v, want %v", got, want)
+	}
+
+	lp.Zones = []Zone{
+		{Name: ZoneHome, Radius: 100},
+		{Name: "work", Latitude: 0.01, Radius: 200},
+	}
+	lp.Hysteresis = 20
+
+	var states []string
+	for _, lat := range []float64{
+		0.0005,  // 56m, inside home
+		0.00095, // 106m, within the hysteresis of home
+		0.0012,  // 133m, outside home
+		0.00095, // 106m, not yet back in home
+		0.0008,  // 89m, back in home
+		0.0099,  // in work
+	} {
+		*p = (*p)[:0]
+		if err := lp.Publish(Location{Latitude: lat}, nil); err != nil {
+			t.Fatalf("could not publish: %v", err)
+		}
+		for _, m := range *p {
+			if m.topic == "phone/state" {
+				states = append(states, m.payload)
+			}
+		}
+	}
+	if want := []string{"home", "away", "home", "work"}; !reflect.DeepEqual(states, want) {
+		t.Errorf("got states %v, want %v", states, want)
+	}
+	if lp.Zone() != "work" {
+		t.Errorf("zone is %q, want work", lp.Zone())
+	}
+
+	*p = (*p)[:0]
+	if err := lp.Reset(); err != nil {
+		t.Fatalf("could not reset: %v", err)
+	}
+	if len(*p) != 1 || (*p)[0].payload != "None" {
+		t.Errorf("reset published %+v", *p)
+	}
+}
@@ -147,7 +147,9 @@ type Lock struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Lock
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Lock
 func (d *Lock) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/lock/%s/config"
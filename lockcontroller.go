@@ -0,0 +1,249 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLockTimeout is how long a LockController waits for the driver to confirm that it
+// locked or unlocked, if Timeout is not set.
+const DefaultLockTimeout = 10 * time.Second
+
+// LockDriver drives a lock. The methods start moving the lock, and the driver confirms when it
+// is done with LockController.Confirm.
+type LockDriver interface {
+	Lock() error
+	Unlock() error
+	// Open unlatches the lock. It is only used if the Lock has a PayloadOpen.
+	Open() error
+}
+
+// LockController binds a LockDriver to a Lock. It passes the commands from Home Assistant to
+// the driver, publishing the locking and unlocking states while it moves, and jammed if the
+// driver doesn't confirm within the Timeout.
+//
+// Home Assistant checks the code against the CodeFormat before sending a command. It only
+// sends the code if the CommandTemplate includes it, as JSON like
+//
+//	{"action": "{{ value }}", "code": "{{ code }}"}
+//
+// and the controller then checks it again. With the default template only the command is
+// sent, and it is accepted as it is.
+type LockController struct {
+	Lock      *Lock
+	Publisher Publisher
+	Driver    LockDriver
+	Clock     Clock
+	Timeout   time.Duration
+
+	// CheckCode checks the code sent with a command, if it is set.
+	CheckCode func(code string) error
+	// OnError is called with the errors from handling commands and publishing.
+	OnError func(error)
+
+	codeFormat *regexp.Regexp
+
+	mu    sync.Mutex
+	state LockState
+	timer Timer
+	gen   int
+}
+
+// NewLockController creates a LockController for l, that drives d and publishes with p. The
+// state is unknown until the driver confirms it.
+func NewLockController(l *Lock, p Publisher, d LockDriver) (*LockController, error) {
+	lc := &LockController{
+		Lock:      l,
+		Publisher: p,
+		Driver:    d,
+		Clock:     RealClock,
+		Timeout:   DefaultLockTimeout,
+	}
+	if l.CodeFormat != "" {
+		// match from the start of the code, as Home Assistant does
+		re, err := regexp.Compile("^(?:" + l.CodeFormat + ")")
+		if err != nil {
+			return nil, fmt.Errorf("could not compile code format: %v", err)
+		}
+		lc.codeFormat = re
+	}
+	return lc, nil
+}
+
+// State returns the current state, or "" if it is not known.
+func (lc *LockController) State() LockState {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.state
+}
+
+// Payload returns the payload published for a state.
+func (lc *LockController) Payload(s LockState) string {
+	switch s {
+	case LockStateLocked:
+		return orDefault(lc.Lock.StateLocked, "LOCKED")
+	case LockStateLocking:
+		return orDefault(lc.Lock.StateLocking, "LOCKING")
+	case LockStateUnlocked:
+		return orDefault(lc.Lock.StateUnlocked, "UNLOCKED")
+	case LockStateUnlocking:
+		return orDefault(lc.Lock.StateUnlocking, "UNLOCKING")
+	case LockStateJammed:
+		return orDefault(lc.Lock.StateJammed, "JAMMED")
+	}
+	return orDefault(lc.Lock.PayloadReset, DefaultPayloadReset)
+}
+
+// Subscribe subscribes the controller to the Lock's command topic.
+func (lc *LockController) Subscribe(s Subscriber) error {
+	if lc.Lock.CommandTopic == "" {
+		return fmt.Errorf("lock has no command topic")
+	}
+	err := s.Subscribe(lc.Lock.CommandTopic, byte(lc.Lock.Qos), func(m Message) {
+		if err := lc.HandleMessage(m); err != nil {
+			lc.error(err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("could not subscribe to %s: %v", lc.Lock.CommandTopic, err)
+	}
+	return nil
+}
+
+func (lc *LockController) error(err error) {
+	if lc.OnError != nil {
+		lc.OnError(err)
+	}
+}
+
+// HandleMessage handles a message on the CommandTopic, which is either the command payload, or
+// JSON with the action and code.
+func (lc *LockController) HandleMessage(m Message) error {
+	var c struct {
+		Action string  `json:"action"`
+		Code   *string `json:"code"`
+	}
+	p := strings.TrimSpace(string(m.Payload))
+	if strings.HasPrefix(p, "{") {
+		if err := json.Unmarshal([]byte(p), &c); err != nil {
+			return fmt.Errorf("could not decode lock command: %v", err)
+		}
+	} else {
+		c.Action = p
+	}
+	return lc.Command(c.Action, c.Code)
+}
+
+// Command runs the command payload sent by Home Assistant. The code is nil if it was not
+// sent.
+func (lc *LockController) Command(payload string, code *string) error {
+	var drive func() error
+	var moving LockState
+	switch {
+	case payload == orDefault(lc.Lock.PayloadLock, "LOCK"):
+		drive, moving = lc.Driver.Lock, LockStateLocking
+	case payload == orDefault(lc.Lock.PayloadUnlock, "UNLOCK"):
+		drive, moving = lc.Driver.Unlock, LockStateUnlocking
+	case lc.Lock.PayloadOpen != "" && payload == lc.Lock.PayloadOpen:
+		drive, moving = lc.Driver.Open, LockStateUnlocking
+	default:
+		return fmt.Errorf("unknown lock command %q", payload)
+	}
+
+	if err := lc.checkCode(code); err != nil {
+		return err
+	}
+
+	lc.mu.Lock()
+	previous := lc.state
+	lc.gen++
+	gen := lc.gen
+	err := lc.set(moving)
+	lc.mu.Unlock()
+	if err != nil {
+		lc.error(err)
+	}
+
+	if err := drive(); err != nil {
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+		if lc.gen == gen {
+			lc.set(previous)
+		}
+		return fmt.Errorf("could not drive lock: %v", err)
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.gen != gen || lc.state != moving {
+		// confirmed already
+		return nil
+	}
+	lc.timer = lc.Clock.AfterFunc(lc.timeout(), func() {
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+		if lc.gen != gen {
+			return
+		}
+		lc.timer = nil
+		if err := lc.set(LockStateJammed); err != nil {
+			lc.error(err)
+		}
+	})
+	return nil
+}
+
+func (lc *LockController) timeout() time.Duration {
+	if lc.Timeout <= 0 {
+		return DefaultLockTimeout
+	}
+	return lc.Timeout
+}
+
+// checkCode checks the code, if the CommandTemplate sends one.
+func (lc *LockController) checkCode(code *string) error {
+	if !strings.Contains(lc.Lock.CommandTemplate, "code") {
+		return nil
+	}
+	if code == nil {
+		return fmt.Errorf("no code was sent")
+	}
+	if lc.codeFormat != nil && !lc.codeFormat.MatchString(*code) {
+		return fmt.Errorf("code does not match the code format")
+	}
+	if lc.CheckCode != nil {
+		return lc.CheckCode(*code)
+	}
+	return nil
+}
+
+// Confirm reports the state of the lock, when the driver has finished moving or the lock was
+// moved by hand. It stops the jam timeout.
+func (lc *LockController) Confirm(s LockState) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.gen++
+	if lc.timer != nil {
+		lc.timer.Stop()
+		lc.timer = nil
+	}
+	return lc.set(s)
+}
+
+// set sets and publishes the state. It must be called with the lock held.
+func (lc *LockController) set(s LockState) error {
+	lc.state = s
+	if lc.Lock.StateTopic == "" {
+		return nil
+	}
+	err := lc.Publisher.Publish(lc.Lock.StateTopic, byte(lc.Lock.Qos), lc.Lock.Retain, []byte(lc.Payload(s)))
+	if err != nil {
+		return fmt.Errorf("could not publish lock state: %v", err)
+	}
+	return nil
+}
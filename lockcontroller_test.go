@@ -0,0 +1,135 @@
+package discovery
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testLockDriver struct {
+	calls []string
+	err   error
+	// confirm is called from the driver methods, to confirm immediately
+	confirm func(string)
+}
+
+func (d *testLockDriver) call(name string) error {
+	d.calls = append(d.calls, name)
+	if d.err == nil && d.confirm != nil {
+		d.confirm(name)
+	}
+	return d.err
+}
+
+func (d *testLockDriver) Lock() error   { return d.call("lock") }
+func (d *testLockDriver) Unlock() error { return d.call("unlock") }
+func (d *testLockDriver) Open() error   { return d.call("open") }
+
+func TestLockController(t *testing.T) {
+	clock := newTestClock()
+	s := testSubscriber{}
+	p := &testPublisher{}
+	d := &testLockDriver{}
+	l := &Lock{
+		CommandTopic:    "door/set",
+		StateTopic:      "door/state",
+		StateJammed:     "STUCK",
+		PayloadOpen:     "OPEN",
+		CodeFormat:      `\d{4}`,
+		CommandTemplate: `{"action": "{{ value }}", "code": "{{ code }}"}`,
+	}
+	lc, err := NewLockController(l, p, d)
+	if err != nil {
+		t.Fatalf("could not create controller: %v", err)
+	}
+	lc.Clock = clock
+	lc.Timeout = 5 * time.Second
+	var errs []error
+	lc.OnError = func(err error) { errs = append(errs, err) }
+	if err := lc.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	s["door/set"](Message{Payload: []byte(`{"action": "LOCK", "code": "12"}`)})
+	s["door/set"](Message{Payload: []byte("LOCK")})
+	if len(errs) != 2 || len(d.calls) != 0 {
+		t.Fatalf("locked with an invalid code: %v", errs)
+	}
+
+	s["door/set"](Message{Payload: []byte(`{"action": "LOCK", "code": "1234"}`)})
+	clock.Advance(4 * time.Second)
+	lc.Confirm(LockStateLocked)
+	clock.Advance(time.Minute)
+	if lc.State() != LockStateLocked {
+		t.Errorf("state is %s, want locked", lc.State())
+	}
+
+	s["door/set"](Message{Payload: []byte(`{"action": "UNLOCK", "code": "1234"}`)})
+	clock.Advance(5 * time.Second)
+	if lc.State() != LockStateJammed {
+		t.Errorf("state is %s, want jammed", lc.State())
+	}
+
+	d.confirm = func(string) { lc.Confirm(LockStateUnlocked) }
+	s["door/set"](Message{Payload: []byte(`{"action": "OPEN", "code": "1234"}`)})
+	clock.Advance(time.Minute)
+	if lc.State() != LockStateUnlocked {
+		t.Errorf("state is %s, want unlocked", lc.State())
+	}
+
+	d.err = errors.New("no battery")
+	code := "1234"
+	if err := lc.Command("LOCK", &code); err == nil {
+		t.Errorf("expected an error from the driver")
+	}
+	if lc.State() != LockStateUnlocked {
+		t.Errorf("state is %s, want unlocked after the driver failed", lc.State())
+	}
+
+	want := []string{"LOCKING", "LOCKED", "UNLOCKING", "STUCK", "UNLOCKING", "UNLOCKED"}
+	if len(*p) < len(want) {
+		t.Fatalf("published %+v, want %v", *p, want)
+	}
+	for i, w := range want {
+		if (*p)[i].payload != w {
+			t.Errorf("published %s, want %s", (*p)[i].payload, w)
+		}
+	}
+	if len(d.calls) != 4 || d.calls[2] != "open" {
+		t.Errorf("driver calls %v", d.calls)
+	}
+}
+
+func TestLockControllerDefaultTemplate(t *testing.T) {
+	s := testSubscriber{}
+	p := &testPublisher{}
+	d := &testLockDriver{}
+	l := &Lock{CommandTopic: "door/set", StateTopic: "door/state", CodeFormat: `\d{4}`, PayloadReset: "RESET"}
+	lc, err := NewLockController(l, p, d)
+	if err != nil {
+		t.Fatalf("could not create controller: %v", err)
+	}
+	lc.CheckCode = func(string) error { return errors.New("code checked") }
+	if err := lc.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	// Home Assistant checked the code, and only sends the command
+	if err := lc.HandleMessage(Message{Payload: []byte("LOCK")}); err != nil {
+		t.Errorf("could not lock: %v", err)
+	}
+	if len(d.calls) != 1 || d.calls[0] != "lock" {
+		t.Errorf("driver calls %v", d.calls)
+	}
+	if lc.Payload("") != "RESET" {
+		t.Errorf("unknown state payload is %q, want the PayloadReset", lc.Payload(""))
+	}
+
+	lc, err = NewLockController(&Lock{}, p, d)
+	if err != nil {
+		t.Fatalf("could not create controller: %v", err)
+	}
+	if err := lc.Subscribe(s); err == nil {
+		t.Errorf("expected an error subscribing without a command topic")
+	}
+}
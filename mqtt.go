@@ -0,0 +1,25 @@
+package discovery
+
+// Message is a message received on an MQTT topic.
+type Message struct {
+	Topic   string
+	Payload []byte
+	// Retained is true if the message was retained by the broker and sent on subscription,
+	// rather than published while subscribed.
+	Retained bool
+	// Duplicate is true if the broker is redelivering a message that may have been received
+	// before.
+	Duplicate bool
+}
+
+// Subscriber is an interface for things that can subscribe a handler to an MQTT topic.
+// It is intended to be a thin wrapper around the MQTT client in use.
+type Subscriber interface {
+	Subscribe(topic string, qos byte, handler func(Message)) error
+}
+
+// Publisher is an interface for things that can publish a payload to an MQTT topic.
+// It is intended to be a thin wrapper around the MQTT client in use.
+type Publisher interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
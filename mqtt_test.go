@@ -0,0 +1,24 @@
+package discovery
+
+// testSubscriber records the handler subscribed to each topic.
+type testSubscriber map[string]func(Message)
+
+func (s testSubscriber) Subscribe(topic string, qos byte, handler func(Message)) error {
+	s[topic] = handler
+	return nil
+}
+
+type published struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  string
+}
+
+// testPublisher records the published messages.
+type testPublisher []published
+
+func (p *testPublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	*p = append(*p, published{topic: topic, qos: qos, retained: retained, payload: string(payload)})
+	return nil
+}
@@ -0,0 +1,115 @@
+package discovery
+
+import "fmt"
+
+type Notify struct {
+
+	// A list of MQTT topics subscribed to receive availability (online/offline) updates. Must not be used together with `availability_topic`
+	// Default: <no value>
+	Availability []Availability `json:"availability,omitempty"`
+
+	// When `availability` is configured, this controls the conditions needed to set the entity to `available`. Valid entries are `all`, `any`, and `latest`. If set to `all`, `payload_available` must be received on all configured availability topics before the entity is marked as online. If set to `any`, `payload_available` must be received on at least one configured availability topic before the entity is marked as online. If set to `latest`, the last `payload_available` or `payload_not_available` received on any configured availability topic controls the availability
+	// Default: latest
+	AvailabilityMode string `json:"availability_mode,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract device's availability from the `availability_topic`. To determine the devices's availability result of this template will be compared to `payload_available` and `payload_not_available`
+	// Default: <no value>
+	AvailabilityTemplate string `json:"availability_template,omitempty"`
+
+	// The MQTT topic subscribed to receive availability (online/offline) updates. Must not be used together with `availability`
+	// Default: <no value>
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `command_topic`. The variable `value` will be assigned with the message, and the variable `title` with the title of the notification
+	// Default: <no value>
+	CommandTemplate string `json:"command_template,omitempty"`
+
+	// The MQTT topic to publish send message commands at
+	// Default: <no value>
+	CommandTopic string `json:"command_topic"`
+
+	// Information about the device this notify entity is a part of to tie it into the [device registry](https://developers.home-assistant.io/docs/en/device_registry_index.html). Only works when [`unique_id`](#unique_id) is set. At least one of identifiers or connections must be present to identify the device
+	// Default: <no value>
+	Device *Device `json:"device,omitempty"`
+
+	// Flag which defines if the entity should be enabled when first added
+	// Default: true
+	EnabledByDefault bool `json:"enabled_by_default,omitempty"`
+
+	// The encoding of the payloads received and published messages. Set to `""` to disable decoding of incoming payload
+	// Default: utf-8
+	Encoding string `json:"encoding,omitempty"`
+
+	// The [category](https://developers.home-assistant.io/docs/core/entity#generic-properties) of the entity
+	// Default: <no value>
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	// Picture URL for the entity
+	// Default: <no value>
+	EntityPicture string `json:"entity_picture,omitempty"`
+
+	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
+	// Default: <no value>
+	Icon string `json:"icon,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the JSON dictionary from messages received on the `json_attributes_topic`. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-template-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTemplate string `json:"json_attributes_template,omitempty"`
+
+	// The MQTT topic subscribed to receive a JSON dictionary payload and then set as sensor attributes. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-topic-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTopic string `json:"json_attributes_topic,omitempty"`
+
+	// The name to use when displaying this notify entity. Can be set to `null` if only the device name is relevant
+	// Default: MQTT notify
+	Name string `json:"name,omitempty"`
+
+	// Used instead of `name` for automatic generation of `entity_id
+	// Default: <no value>
+	ObjectId string `json:"object_id,omitempty"`
+
+	// The payload that represents the available state
+	// Default: online
+	PayloadAvailable string `json:"payload_available,omitempty"`
+
+	// The payload that represents the unavailable state
+	// Default: offline
+	PayloadNotAvailable string `json:"payload_not_available,omitempty"`
+
+	// Must be `notify`. Only allowed and required in [MQTT auto discovery device messages](/integrations/mqtt/#device-discovery-payload)
+	// Default: <no value>
+	Platform string `json:"platform"`
+
+	// The maximum QoS level to be used when receiving and publishing messages
+	// Default: 0
+	Qos int `json:"qos,omitempty"`
+
+	// If the published message should have the retain flag on or not
+	// Default: false
+	Retain bool `json:"retain,omitempty"`
+
+	// An ID that uniquely identifies this notify entity device. If two notify entities have the same unique ID, Home Assistant will raise an exception. Required when used with device-based discovery
+	// Default: <no value>
+	UniqueId string `json:"unique_id,omitempty"`
+}
+
+// AnnounceTopic returns the topic to announce the discoverable Notify
+// Topic has the format below:
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
+// 'object_id' is either the UniqueId, the Name, or a hash of the Notify
+func (d *Notify) AnnounceTopic(prefix string) string {
+	topicFormat := "%s/notify/%s/config"
+	objectID := ""
+	switch {
+	case d.UniqueId != "":
+		objectID = d.UniqueId
+	case d.Name != "":
+		objectID = d.Name
+	default:
+		objectID = hash(d)
+	}
+
+	return fmt.Sprintf(topicFormat, prefix, objectID)
+}
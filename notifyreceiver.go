@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NotifyMessage is a message sent to a Notify entity.
+type NotifyMessage struct {
+	Message string
+	// Title is only set if the command template includes the title.
+	Title string
+	// Payload is the payload as it was received.
+	Payload []byte
+}
+
+// templateVariable matches the variables Home Assistant assigns when rendering the command
+// template of a Notify.
+var templateVariable = regexp.MustCompile(`{{-?\s*(value|title)\s*-?}}`)
+
+// NotifyReceiver receives the messages sent to a Notify entity, and passes them to OnMessage.
+//
+// If the Notify has a CommandTemplate that only substitutes `value` and `title`, like
+//
+//	{"title": "{{ title }}", "message": "{{ value }}"}
+//
+// the message and title are extracted from the payload by reversing the template. Otherwise
+// the whole payload is the message.
+type NotifyReceiver struct {
+	Notify *Notify
+
+	OnMessage func(NotifyMessage) error
+	// OnError is called with the errors from handling messages.
+	OnError func(error)
+
+	pattern *regexp.Regexp
+	groups  []string
+}
+
+// NewNotifyReceiver creates a NotifyReceiver for n, reversing its CommandTemplate if that is
+// possible.
+func NewNotifyReceiver(n *Notify) *NotifyReceiver {
+	nr := &NotifyReceiver{Notify: n}
+	nr.pattern, nr.groups = reverseTemplate(n.CommandTemplate)
+	return nr
+}
+
+// reverseTemplate returns a pattern that extracts the variables from the output of template
+// t, and the names of its groups. It returns nil if t doesn't use value, or uses anything
+// other than value and title.
+func reverseTemplate(t string) (*regexp.Regexp, []string) {
+	var pattern strings.Builder
+	var groups []string
+	last := 0
+	for _, loc := range templateVariable.FindAllStringSubmatchIndex(t, -1) {
+		pattern.WriteString(regexp.QuoteMeta(t[last:loc[0]]))
+		pattern.WriteString("(.*?)")
+		groups = append(groups, t[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	rest := t[last:]
+	literal := templateVariable.ReplaceAllString(t, "")
+	if strings.Contains(literal, "{{") || strings.Contains(literal, "{%") || !contains(groups, "value") {
+		return nil, nil
+	}
+	pattern.WriteString(regexp.QuoteMeta(rest))
+	return regexp.MustCompile(`(?s)^` + pattern.String() + `$`), groups
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe subscribes the receiver to the Notify's command topic.
+func (nr *NotifyReceiver) Subscribe(s Subscriber) error {
+	if nr.Notify.CommandTopic == "" {
+		return fmt.Errorf("notify has no command topic")
+	}
+	err := s.Subscribe(nr.Notify.CommandTopic, byte(nr.Notify.Qos), func(m Message) {
+		if err := nr.HandleMessage(m); err != nil && nr.OnError != nil {
+			nr.OnError(err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("could not subscribe to %s: %v", nr.Notify.CommandTopic, err)
+	}
+	return nil
+}
+
+// HandleMessage decodes a message on the CommandTopic, and passes it to OnMessage.
+func (nr *NotifyReceiver) HandleMessage(m Message) error {
+	nm, err := nr.Decode(m.Payload)
+	if err != nil {
+		return err
+	}
+	if nr.OnMessage == nil {
+		return nil
+	}
+	return nr.OnMessage(nm)
+}
+
+// Decode decodes the payload of a message.
+func (nr *NotifyReceiver) Decode(payload []byte) (NotifyMessage, error) {
+	nm := NotifyMessage{Payload: payload}
+	if nr.pattern == nil {
+		nm.Message = string(payload)
+		return nm, nil
+	}
+
+	match := nr.pattern.FindSubmatch(payload)
+	if match == nil {
+		return NotifyMessage{}, fmt.Errorf("payload %q does not match the command template", payload)
+	}
+	// a variable can be used more than once, the first use is kept
+	for i := len(nr.groups) - 1; i >= 0; i-- {
+		switch nr.groups[i] {
+		case "value":
+			nm.Message = string(match[i+1])
+		case "title":
+			nm.Title = string(match[i+1])
+		}
+	}
+	return nm, nil
+}
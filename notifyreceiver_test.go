@@ -0,0 +1,69 @@
+package discovery
+
+import "testing"
+
+func TestNotifyReceiver(t *testing.T) {
+	for _, tc := range []struct {
+		template string
+		payload  string
+		want     NotifyMessage
+		fail     bool
+	}{
+		{"", "Hello: world", NotifyMessage{Message: "Hello: world"}, false},
+		{"{{ value }}", "Hello", NotifyMessage{Message: "Hello"}, false},
+		{"{{title}}: {{value}}", "Door: open: now", NotifyMessage{Title: "Door", Message: "open: now"}, false},
+		{`{"title": "{{ title }}", "message": "{{ value }}"}`, `{"title": "Door", "message": "two
+lines"}`, NotifyMessage{Title: "Door", Message: "two\nlines"}, false},
+		{"[{{ value }}]", "Hello", NotifyMessage{}, true},
+		{"{{ value | upper }}", "HELLO", NotifyMessage{Message: "HELLO"}, false},
+		{"{{ title }}", "Door", NotifyMessage{Message: "Door"}, false},
+	} {
+		nr := NewNotifyReceiver(&Notify{CommandTemplate: tc.template})
+		got, err := nr.Decode([]byte(tc.payload))
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%s: expected an error decoding %q", tc.template, tc.payload)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: could not decode %q: %v", tc.template, tc.payload, err)
+			continue
+		}
+		if got.Message != tc.want.Message || got.Title != tc.want.Title || string(got.Payload) != tc.payload {
+			t.Errorf("%s: got %+v, want %+v", tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestNotifyReceiverSubscribe(t *testing.T) {
+	s := testSubscriber{}
+	nr := NewNotifyReceiver(&Notify{CommandTopic: "ticker/message", CommandTemplate: "{{ title }}|{{ value }}"})
+	var got []NotifyMessage
+	nr.OnMessage = func(nm NotifyMessage) error {
+		got = append(got, nm)
+		return nil
+	}
+	var errs []error
+	nr.OnError = func(err error) { errs = append(errs, err) }
+	if err := nr.Subscribe(s); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	s["ticker/message"](Message{Payload: []byte("Bins|Put the bins out")})
+	s["ticker/message"](Message{Payload: []byte("Put the bins out")})
+
+	if len(got) != 1 || got[0].Title != "Bins" || got[0].Message != "Put the bins out" {
+		t.Errorf("got messages %+v", got)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want 1", errs)
+	}
+
+	if err := NewNotifyReceiver(&Notify{}).Subscribe(s); err == nil {
+		t.Errorf("expected an error subscribing without a command topic")
+	}
+	if _, ok := s[""]; ok {
+		t.Errorf("subscribed to the empty topic")
+	}
+}
@@ -123,7 +123,9 @@ type Number struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Number
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Number
 func (d *Number) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/number/%s/config"
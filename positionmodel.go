@@ -0,0 +1,284 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Motion is the direction a cover or valve is moving in.
+type Motion string
+
+const (
+	MotionStopped Motion = "stopped"
+	MotionOpening Motion = "opening"
+	MotionClosing Motion = "closing"
+)
+
+// PositionCommandKind is the kind of a command sent to a cover or valve.
+type PositionCommandKind int
+
+const (
+	PositionCommandOpen PositionCommandKind = iota
+	PositionCommandClose
+	PositionCommandStop
+	PositionCommandSetPosition
+)
+
+// PositionCommand is a command sent to a cover or valve. Position is the requested position as
+// a percentage, and is only set for PositionCommandSetPosition.
+type PositionCommand struct {
+	Kind     PositionCommandKind
+	Position float64
+}
+
+// PositionModel is the state of a cover or valve: the direction it is moving in, its position,
+// and the position it is moving to.
+//
+// Positions are percentages, 0 being closed and 100 being open. They are scaled to the
+// range from PositionClosed to PositionOpen when talking to Home Assistant.
+type PositionModel struct {
+	PositionOpen   int
+	PositionClosed int
+
+	StateOpen    string
+	StateOpening string
+	StateClosed  string
+	StateClosing string
+	// StateStopped is the state of a cover stopped between open and closed. If it is empty,
+	// the state is StateOpen instead, as for a valve.
+	StateStopped string
+
+	PayloadOpen  string
+	PayloadClose string
+	PayloadStop  string
+
+	// ReportsPosition is true if the position is known, and can be set.
+	ReportsPosition bool
+
+	mu       sync.Mutex
+	motion   Motion
+	position float64
+	target   float64
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func intOrDefault(i *int, def int) int {
+	if i == nil {
+		return def
+	}
+	return *i
+}
+
+// NewCoverModel creates a PositionModel from the configuration of c. The cover starts stopped
+// and closed.
+func NewCoverModel(c *Cover) *PositionModel {
+	return &PositionModel{
+		PositionOpen:    intOrDefault(c.PositionOpen, 100),
+		PositionClosed:  intOrDefault(c.PositionClosed, 0),
+		StateOpen:       orDefault(c.StateOpen, "open"),
+		StateOpening:    orDefault(c.StateOpening, "opening"),
+		StateClosed:     orDefault(c.StateClosed, "closed"),
+		StateClosing:    orDefault(c.StateClosing, "closing"),
+		StateStopped:    orDefault(c.StateStopped, "stopped"),
+		PayloadOpen:     orDefault(c.PayloadOpen, "OPEN"),
+		PayloadClose:    orDefault(c.PayloadClose, "CLOSE"),
+		PayloadStop:     orDefault(c.PayloadStop, "STOP"),
+		ReportsPosition: c.PositionTopic != "" || c.SetPositionTopic != "",
+		motion:          MotionStopped,
+	}
+}
+
+// NewValveModel creates a PositionModel from the configuration of v. The valve starts stopped
+// and closed.
+func NewValveModel(v *Valve) *PositionModel {
+	return &PositionModel{
+		PositionOpen:    intOrDefault(v.PositionOpen, 100),
+		PositionClosed:  intOrDefault(v.PositionClosed, 0),
+		StateOpen:       orDefault(v.StateOpen, "open"),
+		StateOpening:    orDefault(v.StateOpening, "opening"),
+		StateClosed:     orDefault(v.StateClosed, "closed"),
+		StateClosing:    orDefault(v.StateClosing, "closing"),
+		PayloadOpen:     orDefault(v.PayloadOpen, "OPEN"),
+		PayloadClose:    orDefault(v.PayloadClose, "CLOSE"),
+		PayloadStop:     v.PayloadStop,
+		ReportsPosition: v.ReportsPosition,
+		motion:          MotionStopped,
+	}
+}
+
+func clampPercent(p float64) float64 {
+	return math.Max(0, math.Min(100, p))
+}
+
+// Scale converts a percentage to a position between PositionClosed and PositionOpen.
+func (m *PositionModel) Scale(percent float64) int {
+	span := float64(m.PositionOpen - m.PositionClosed)
+	return m.PositionClosed + int(math.Round(clampPercent(percent)*span/100))
+}
+
+// Percent converts a position between PositionClosed and PositionOpen to a percentage.
+func (m *PositionModel) Percent(position int) float64 {
+	return m.percent(float64(position))
+}
+
+func (m *PositionModel) percent(position float64) float64 {
+	span := float64(m.PositionOpen - m.PositionClosed)
+	return clampPercent((position - float64(m.PositionClosed)) * 100 / span)
+}
+
+// Motion returns the direction the cover or valve is moving in.
+func (m *PositionModel) Motion() Motion {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.motion
+}
+
+// Position returns the position as a percentage.
+func (m *PositionModel) Position() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.position
+}
+
+// Target returns the position being moved to as a percentage. When stopped it is the position.
+func (m *PositionModel) Target() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.target
+}
+
+// Open starts moving to the open position.
+func (m *PositionModel) Open() {
+	m.MoveTo(100)
+}
+
+// Close starts moving to the closed position.
+func (m *PositionModel) Close() {
+	m.MoveTo(0)
+}
+
+// MoveTo starts moving to the position given as a percentage. Moving to the current position
+// stops.
+func (m *PositionModel) MoveTo(percent float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.target = clampPercent(percent)
+	switch {
+	case m.target > m.position:
+		m.motion = MotionOpening
+	case m.target < m.position:
+		m.motion = MotionClosing
+	default:
+		m.motion = MotionStopped
+	}
+}
+
+// Stop stops moving, at the current position.
+func (m *PositionModel) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.motion = MotionStopped
+	m.target = m.position
+}
+
+// SetPosition sets the position as a percentage, for example as reported by the device or
+// estimated while moving. Reaching or passing the target while moving stops.
+func (m *PositionModel) SetPosition(percent float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.position = clampPercent(percent)
+	switch m.motion {
+	case MotionOpening:
+		if m.position < m.target {
+			return
+		}
+	case MotionClosing:
+		if m.position > m.target {
+			return
+		}
+	}
+	m.motion = MotionStopped
+	m.target = m.position
+}
+
+// State returns the state to publish to Home Assistant.
+func (m *PositionModel) State() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case m.motion == MotionOpening:
+		return m.StateOpening
+	case m.motion == MotionClosing:
+		return m.StateClosing
+	case m.position <= 0:
+		return m.StateClosed
+	case m.position >= 100 || m.StateStopped == "":
+		return m.StateOpen
+	}
+	return m.StateStopped
+}
+
+// ValvePayload returns the payload to publish to the state topic of a Valve. When the
+// position is reported it is the state and position as JSON, otherwise it is the state.
+func (m *PositionModel) ValvePayload() ([]byte, error) {
+	if !m.ReportsPosition {
+		return []byte(m.State()), nil
+	}
+
+	return json.Marshal(struct {
+		State    string `json:"state"`
+		Position int    `json:"position"`
+	}{
+		State:    m.State(),
+		Position: m.Scale(m.Position()),
+	})
+}
+
+// Decode decodes a command sent by Home Assistant. Numeric payloads are positions between
+// PositionClosed and PositionOpen, and are only accepted if the position is reported.
+func (m *PositionModel) Decode(payload []byte) (PositionCommand, error) {
+	p := strings.TrimSpace(string(payload))
+	switch {
+	case p == "":
+	case p == m.PayloadOpen:
+		return PositionCommand{Kind: PositionCommandOpen}, nil
+	case p == m.PayloadClose:
+		return PositionCommand{Kind: PositionCommandClose}, nil
+	case p == m.PayloadStop:
+		return PositionCommand{Kind: PositionCommandStop}, nil
+	case m.ReportsPosition:
+		pos, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			break
+		}
+		return PositionCommand{Kind: PositionCommandSetPosition, Position: m.percent(pos)}, nil
+	}
+	return PositionCommand{}, fmt.Errorf("unknown command %q", p)
+}
+
+// Apply starts or stops moving as the command requests.
+func (m *PositionModel) Apply(c PositionCommand) {
+	switch c.Kind {
+	case PositionCommandOpen:
+		m.Open()
+	case PositionCommandClose:
+		m.Close()
+	case PositionCommandStop:
+		m.Stop()
+	case PositionCommandSetPosition:
+		m.MoveTo(c.Position)
+	}
+}
@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPositionModel(t *testing.T) {
+	open, closed := 0, 255
+	cover := &Cover{PositionOpen: &open, PositionClosed: &closed, SetPositionTopic: "blind/set"}
+	if bs, _ := json.Marshal(cover); !strings.Contains(string(bs), `"position_open":0`) {
+		t.Errorf("position_open 0 is omitted: %s", bs)
+	}
+	m := NewCoverModel(cover)
+
+	if got := m.Scale(100); got != 0 {
+		t.Errorf("open scales to %d, want 0", got)
+	}
+	if got := m.Scale(25); got != 191 {
+		t.Errorf("25%% scales to %d, want 191", got)
+	}
+	if got := m.Percent(51); got != 80 {
+		t.Errorf("51 is %v%%, want 80%%", got)
+	}
+
+	if m.State() != "closed" {
+		t.Fatalf("cover starts %s, want closed", m.State())
+	}
+
+	// Home Assistant uses its defaults for the bounds that are not set
+	closed = 5
+	if d := NewCoverModel(&Cover{PositionClosed: &closed}); d.PositionOpen != 100 || d.PositionClosed != 5 {
+		t.Errorf("range is %d to %d, want 5 to 100", d.PositionClosed, d.PositionOpen)
+	}
+
+	c, err := m.Decode([]byte("OPEN"))
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	m.Apply(c)
+	if m.State() != "opening" {
+		t.Fatalf("cover is %s, want opening", m.State())
+	}
+
+	m.SetPosition(40)
+	m.Stop()
+	if m.Motion() != MotionStopped || m.State() != "stopped" {
+		t.Fatalf("cover is %s, want stopped", m.State())
+	}
+
+	c, err = m.Decode([]byte("204"))
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	if c.Kind != PositionCommandSetPosition || c.Position != 20 {
+		t.Fatalf("decoded %+v, want set position 20", c)
+	}
+	m.Apply(c)
+	if m.State() != "closing" {
+		t.Fatalf("cover is %s, want closing", m.State())
+	}
+	m.SetPosition(30)
+	if m.Motion() != MotionClosing {
+		t.Fatalf("cover stopped before reaching the target")
+	}
+	m.SetPosition(15)
+	if m.Motion() != MotionStopped || m.Position() != 15 {
+		t.Fatalf("cover did not stop at the target: %s at %v", m.Motion(), m.Position())
+	}
+
+	if _, err := m.Decode([]byte("sideways")); err == nil {
+		t.Errorf("expected an error decoding an unknown command")
+	}
+}
+
+func TestValveModel(t *testing.T) {
+	v := NewValveModel(&Valve{})
+	if _, err := v.Decode([]byte("50")); err == nil {
+		t.Errorf("expected an error setting the position of a valve that does not report it")
+	}
+	if _, err := v.Decode([]byte("")); err == nil {
+		t.Errorf("expected an error for an empty command, as the valve does not support stop")
+	}
+
+	v.Open()
+	v.SetPosition(50)
+	v.Stop()
+	bs, _ := v.ValvePayload()
+	if string(bs) != "open" {
+		t.Errorf("partially open valve is %s, want open", bs)
+	}
+
+	ten := 10
+	v = NewValveModel(&Valve{ReportsPosition: true, PositionOpen: &ten})
+	v.MoveTo(50)
+	v.SetPosition(20)
+	bs, err := v.ValvePayload()
+	if err != nil {
+		t.Fatalf("could not create payload: %v", err)
+	}
+	if string(bs) != `{"state":"opening","position":2}` {
+		t.Errorf("got payload %s", bs)
+	}
+}
@@ -0,0 +1,47 @@
+package discovery
+
+import "sort"
+
+// ComponentInfo describes a component that supports discovery.
+type ComponentInfo struct {
+	// Name of the component, as used in the discovery topic.
+	Name string
+	// New returns a new, empty discovery configuration for the component. The underlying type
+	// is a pointer to the component's struct, for example *AlarmControlPanel.
+	New func() Announcer
+	// Fields are the json keys of the component's configuration.
+	Fields []string
+	// HasCommandTopic is true if the component can be configured with a command_topic.
+	HasCommandTopic bool
+	// HasStateTopic is true if the component can be configured with a state_topic.
+	HasStateTopic bool
+	// SupportsUniqueID is true if the component can be configured with a unique_id.
+	SupportsUniqueID bool
+}
+
+// LookupComponent returns the ComponentInfo for the component with the given name, for example
+// "alarm_control_panel".
+func LookupComponent(name string) (ComponentInfo, bool) {
+	ci, ok := registry[name]
+	return ci, ok
+}
+
+// New returns a new, empty discovery configuration for the component with the given name, or
+// nil if there is no such component.
+func New(name string) Announcer {
+	ci, ok := registry[name]
+	if !ok {
+		return nil
+	}
+	return ci.New()
+}
+
+// RegisteredComponents returns the ComponentInfo of every component, sorted by name.
+func RegisteredComponents() []ComponentInfo {
+	cis := make([]ComponentInfo, 0, len(registry))
+	for _, ci := range registry {
+		cis = append(cis, ci)
+	}
+	sort.Slice(cis, func(i, j int) bool { return cis[i].Name < cis[j].Name })
+	return cis
+}
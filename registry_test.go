@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	cis := RegisteredComponents()
+	if len(cis) != len(registry) {
+		t.Fatalf("got %d components, want %d", len(cis), len(registry))
+	}
+
+	for _, ci := range cis {
+		a := New(ci.Name)
+		if a == nil {
+			t.Fatalf("no configuration for %s", ci.Name)
+		}
+		if !strings.Contains(a.AnnounceTopic("homeassistant"), "/"+ci.Name+"/") {
+			t.Errorf("%s announces to %s", ci.Name, a.AnnounceTopic("homeassistant"))
+		}
+
+		// the field list should match the json tags of the struct
+		fields := []string{}
+		rt := reflect.TypeOf(a).Elem()
+		for i := 0; i < rt.NumField(); i++ {
+			fields = append(fields, strings.Split(rt.Field(i).Tag.Get("json"), ",")[0])
+		}
+		sort.Strings(fields)
+		if !reflect.DeepEqual(fields, ci.Fields) {
+			t.Errorf("%s has fields %v, want %v", ci.Name, ci.Fields, fields)
+		}
+	}
+
+	ci, ok := LookupComponent("alarm_control_panel")
+	if !ok {
+		t.Fatalf("could not find alarm_control_panel")
+	}
+	if _, ok := ci.New().(*AlarmControlPanel); !ok {
+		t.Errorf("alarm_control_panel creates a %T", ci.New())
+	}
+	if !ci.HasCommandTopic || !ci.HasStateTopic || !ci.SupportsUniqueID {
+		t.Errorf("alarm_control_panel has the wrong capabilities: %+v", ci)
+	}
+
+	ci, _ = LookupComponent(ComponentTag)
+	if ci.HasCommandTopic || ci.HasStateTopic || ci.SupportsUniqueID {
+		t.Errorf("tag has the wrong capabilities: %+v", ci)
+	}
+
+	if _, ok := LookupComponent("not_a_component"); ok {
+		t.Errorf("found a component that does not exist")
+	}
+	if New("not_a_component") != nil {
+		t.Errorf("created a component that does not exist")
+	}
+}
@@ -95,7 +95,9 @@ type Scene struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Scene
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Scene
 func (d *Scene) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/scene/%s/config"
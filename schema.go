@@ -0,0 +1,24 @@
+package discovery
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed schema/*.json
+var schemas embed.FS
+
+// DeviceSchema is the name of the JSON Schema for device discovery payloads, which hold the
+// configuration of several components of a device.
+const DeviceSchema = "device"
+
+// JSONSchema returns the JSON Schema for the discovery payload of the component, for example
+// ComponentBinarySensor. Use DeviceSchema for the schema of device discovery payloads.
+// The schemas are generated from the same documentation as the discovery structs.
+func JSONSchema(component string) ([]byte, error) {
+	bs, err := schemas.ReadFile("schema/" + component + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no schema for component %q", component)
+	}
+	return bs, nil
+}
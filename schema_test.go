@@ -0,0 +1,28 @@
+package discovery
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	for _, c := range []string{ComponentBinarySensor, ComponentLight, DeviceSchema} {
+		bs, err := JSONSchema(c)
+		if err != nil {
+			t.Fatalf("could not get schema for %s: %v", c, err)
+		}
+
+		s := map[string]interface{}{}
+		err = json.Unmarshal(bs, &s)
+		if err != nil {
+			t.Fatalf("schema for %s is not valid json: %v", c, err)
+		}
+		if s["type"] != "object" {
+			t.Errorf("schema for %s is not for an object: %v", c, s["type"])
+		}
+	}
+
+	if _, err := JSONSchema("not_a_component"); err == nil {
+		t.Errorf("expected an error for an unknown component")
+	}
+}
@@ -103,7 +103,9 @@ type Select struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Select
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Select
 func (d *Select) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/select/%s/config"
@@ -123,7 +123,9 @@ type Sensor struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Sensor
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Sensor
 func (d *Sensor) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/sensor/%s/config"
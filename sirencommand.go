@@ -0,0 +1,103 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SirenCommand is a command sent to a Siren. Tone, VolumeLevel and Duration are only set if
+// they were part of the command.
+type SirenCommand struct {
+	On          bool
+	Tone        string
+	VolumeLevel *float64
+	// Duration is the number of seconds the siren should sound for.
+	Duration *int
+}
+
+// DecodeCommand decodes a command sent to the Siren's command topic, either the plain
+// PayloadOn or PayloadOff, or the JSON form Home Assistant sends when no command template is
+// configured:
+//
+//	{"state": "ON", "tone": "fire", "volume_level": 0.8, "duration": 30}
+//
+// It returns an error for tones that are not in AvailableTones, and for volumes and durations
+// the siren does not support.
+func (s *Siren) DecodeCommand(payload []byte) (SirenCommand, error) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 || payload[0] != '{' {
+		on, err := s.decodeState(string(payload))
+		return SirenCommand{On: on}, err
+	}
+
+	var raw struct {
+		State       string      `json:"state"`
+		Tone        interface{} `json:"tone"`
+		VolumeLevel *float64    `json:"volume_level"`
+		Duration    *int        `json:"duration"`
+	}
+	err := json.Unmarshal(payload, &raw)
+	if err != nil {
+		return SirenCommand{}, fmt.Errorf("could not decode siren command: %v", err)
+	}
+
+	c := SirenCommand{
+		VolumeLevel: raw.VolumeLevel,
+		Duration:    raw.Duration,
+	}
+	c.On, err = s.decodeState(raw.State)
+	if err != nil {
+		return SirenCommand{}, err
+	}
+
+	// tones can be numbers as well as strings
+	if raw.Tone != nil {
+		c.Tone = fmt.Sprint(raw.Tone)
+		if !s.hasTone(c.Tone) {
+			return SirenCommand{}, fmt.Errorf("tone %q is not available", c.Tone)
+		}
+	}
+	if c.VolumeLevel != nil {
+		if !supported(s.SupportVolumeSet) {
+			return SirenCommand{}, fmt.Errorf("siren does not support setting the volume")
+		}
+		if *c.VolumeLevel < 0 || *c.VolumeLevel > 1 {
+			return SirenCommand{}, fmt.Errorf("volume level %v is outside 0 to 1", *c.VolumeLevel)
+		}
+	}
+	if c.Duration != nil {
+		if !supported(s.SupportDuration) {
+			return SirenCommand{}, fmt.Errorf("siren does not support a duration")
+		}
+		if *c.Duration < 0 {
+			return SirenCommand{}, fmt.Errorf("duration %d is negative", *c.Duration)
+		}
+	}
+
+	return c, nil
+}
+
+func (s *Siren) decodeState(state string) (bool, error) {
+	switch state {
+	case orDefault(s.PayloadOn, "ON"):
+		return true, nil
+	case orDefault(s.PayloadOff, "OFF"):
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown siren state %q", state)
+}
+
+func (s *Siren) hasTone(tone string) bool {
+	for _, t := range s.AvailableTones {
+		if t == tone {
+			return true
+		}
+	}
+	return false
+}
+
+// supported returns the value of an optional feature flag, which defaults to true.
+func supported(b *bool) bool {
+	return b == nil || *b
+}
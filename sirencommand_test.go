@@ -0,0 +1,51 @@
+package discovery
+
+import "testing"
+
+func TestSirenDecodeCommand(t *testing.T) {
+	no := false
+	s := &Siren{
+		AvailableTones:   []string{"fire", "ping", "1"},
+		SupportVolumeSet: &no,
+	}
+
+	c, err := s.DecodeCommand([]byte("ON"))
+	if err != nil || !c.On {
+		t.Fatalf("could not decode ON: %+v, %v", c, err)
+	}
+	c, err = s.DecodeCommand([]byte(`{"state":"OFF"}`))
+	if err != nil || c.On {
+		t.Fatalf("could not decode OFF: %+v, %v", c, err)
+	}
+
+	c, err = s.DecodeCommand([]byte(`{"state":"ON","tone":"fire","duration":30}`))
+	if err != nil {
+		t.Fatalf("could not decode command: %v", err)
+	}
+	if !c.On || c.Tone != "fire" || c.Duration == nil || *c.Duration != 30 || c.VolumeLevel != nil {
+		t.Errorf("decoded %+v", c)
+	}
+
+	c, err = s.DecodeCommand([]byte(`{"state":"ON","tone":1}`))
+	if err != nil || c.Tone != "1" {
+		t.Errorf("could not decode a numeric tone: %+v, %v", c, err)
+	}
+
+	for _, p := range []string{
+		"TOGGLE",
+		`{"state":"ON","tone":"police"}`,
+		`{"state":"ON","volume_level":0.8}`,
+		`{"state":"ON","duration":-1}`,
+		`{"state":`,
+	} {
+		if _, err := s.DecodeCommand([]byte(p)); err == nil {
+			t.Errorf("expected an error decoding %s", p)
+		}
+	}
+
+	s.SupportVolumeSet = nil
+	c, err = s.DecodeCommand([]byte(`{"state":"ON","volume_level":0.8}`))
+	if err != nil || c.VolumeLevel == nil || *c.VolumeLevel != 0.8 {
+		t.Errorf("could not decode the volume level: %+v, %v", c, err)
+	}
+}
@@ -127,7 +127,9 @@ type Switch struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Switch
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Switch
 func (d *Switch) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/switch/%s/config"
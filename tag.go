@@ -19,7 +19,9 @@ type Tag struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Tag
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Tag
 func (d *Tag) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/tag/%s/config"
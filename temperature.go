@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TemperatureSettings are the unit, precision and set point limits of a Climate or
+// WaterHeater, with the defaults Home Assistant uses for any that are not configured.
+type TemperatureSettings struct {
+	Unit      string
+	Precision float64
+	Min       float64
+	Max       float64
+	Initial   float64
+}
+
+// temperatureDefaults are the defaults of the set point limits in each unit.
+type temperatureDefaults struct {
+	min, max, initial float64
+}
+
+var (
+	climateDefaults = map[string]temperatureDefaults{
+		TemperatureUnitC: {min: 7, max: 35, initial: 21},
+		TemperatureUnitF: {min: 44.6, max: 95, initial: 69.8},
+	}
+	waterHeaterDefaults = map[string]temperatureDefaults{
+		TemperatureUnitC: {min: 43.3, max: 60, initial: 43.3},
+		TemperatureUnitF: {min: 110, max: 140, initial: 110},
+	}
+)
+
+// newTemperatureSettings parses the configured settings. When the unit is not configured Home
+// Assistant uses the system unit, which is assumed to be Celsius.
+func newTemperatureSettings(unit, precision, min, max, initial string, defaults map[string]temperatureDefaults) (TemperatureSettings, error) {
+	ts := TemperatureSettings{Unit: TemperatureUnitC, Precision: 0.1}
+	switch unit {
+	case "", TemperatureUnitC:
+	case TemperatureUnitF:
+		ts.Unit = TemperatureUnitF
+		ts.Precision = 1
+	default:
+		return ts, fmt.Errorf("unknown temperature unit %q", unit)
+	}
+
+	d := defaults[ts.Unit]
+	for _, s := range []struct {
+		name  string
+		value string
+		v     *float64
+		def   float64
+	}{
+		{"precision", precision, &ts.Precision, ts.Precision},
+		{"min_temp", min, &ts.Min, d.min},
+		{"max_temp", max, &ts.Max, d.max},
+		{"initial", initial, &ts.Initial, d.initial},
+	} {
+		*s.v = s.def
+		if s.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(s.value, 64)
+		if err != nil {
+			return ts, fmt.Errorf("could not parse %s: %v", s.name, err)
+		}
+		*s.v = f
+	}
+
+	if ts.Precision <= 0 {
+		return ts, fmt.Errorf("precision must be positive")
+	}
+	if ts.Min > ts.Max {
+		return ts, fmt.Errorf("min_temp %v is more than max_temp %v", ts.Min, ts.Max)
+	}
+
+	return ts, nil
+}
+
+// TemperatureSettings returns the temperature settings of the Climate.
+func (c *Climate) TemperatureSettings() (TemperatureSettings, error) {
+	return newTemperatureSettings(c.TemperatureUnit, c.Precision, c.MinTemp, c.MaxTemp, c.Initial, climateDefaults)
+}
+
+// TemperatureSettings returns the temperature settings of the WaterHeater.
+func (w *WaterHeater) TemperatureSettings() (TemperatureSettings, error) {
+	return newTemperatureSettings(w.TemperatureUnit, w.Precision, w.MinTemp, w.MaxTemp, w.Initial, waterHeaterDefaults)
+}
+
+// Round rounds the temperature to the precision.
+func (ts TemperatureSettings) Round(t float64) float64 {
+	r := math.Round(t/ts.Precision) * ts.Precision
+	// remove the noise of the multiplication, so 21.400000000000002 becomes 21.4
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(r, 'f', ts.decimals(), 64), 64)
+	return f
+}
+
+// Clamp limits the temperature to the range from Min to Max.
+func (ts TemperatureSettings) Clamp(t float64) float64 {
+	return math.Max(ts.Min, math.Min(ts.Max, t))
+}
+
+// Format rounds the temperature to the precision and formats it for publishing.
+func (ts TemperatureSettings) Format(t float64) string {
+	return strconv.FormatFloat(ts.Round(t), 'f', ts.decimals(), 64)
+}
+
+// Parse parses a temperature received from Home Assistant. It returns an error if the
+// temperature is outside the range from Min to Max.
+func (ts TemperatureSettings) Parse(payload []byte) (float64, error) {
+	t, err := strconv.ParseFloat(strings.TrimSpace(string(payload)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse temperature: %v", err)
+	}
+	if t < ts.Min || t > ts.Max {
+		return 0, fmt.Errorf("temperature %v is outside %v to %v", t, ts.Min, ts.Max)
+	}
+	return ts.Round(t), nil
+}
+
+// decimals returns the number of decimals needed to show the precision.
+func (ts TemperatureSettings) decimals() int {
+	d := 0
+	for p := ts.Precision; p < 1 && d < 6; p *= 10 {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultTextMax is the maximum length of the value of a Text when Max is not set.
+const DefaultTextMax = 255
+
+// TextHandler checks the values Home Assistant sets on a Text against its Min and Max length
+// and its Pattern before passing them to Set.
+//
+// Values that are not valid, or that Set fails on, never reach the device. Instead OnInvalid is
+// called and the current value is published to the StateTopic again, so Home Assistant shows
+// the value the device actually has.
+type TextHandler struct {
+	Text *Text
+	// Publisher is used to publish the value to the StateTopic. It may be nil if the Text has
+	// no StateTopic.
+	Publisher Publisher
+	Set       func(value string) error
+	OnInvalid func(value string, err error)
+
+	pattern *regexp.Regexp
+	mu      sync.Mutex
+	value   string
+}
+
+// NewTextHandler creates a TextHandler for t that publishes its value with p and sets it with
+// set. It returns an error if the Text's Pattern is not a valid regular expression.
+func NewTextHandler(t *Text, p Publisher, set func(value string) error) (*TextHandler, error) {
+	h := &TextHandler{
+		Text:      t,
+		Publisher: p,
+		Set:       set,
+	}
+
+	if t.Pattern != "" {
+		// Home Assistant matches the pattern at the start of the value, like python's re.match
+		re, err := regexp.Compile("^(?:" + t.Pattern + ")")
+		if err != nil {
+			return nil, fmt.Errorf("could not compile pattern: %v", err)
+		}
+		h.pattern = re
+	}
+
+	return h, nil
+}
+
+// Validate returns an error if value is too short or too long, or does not match the pattern.
+func (h *TextHandler) Validate(value string) error {
+	max := h.Text.Max
+	if max == 0 {
+		max = DefaultTextMax
+	}
+
+	n := utf8.RuneCountInString(value)
+	switch {
+	case n < h.Text.Min:
+		return fmt.Errorf("text is %d characters, minimum is %d", n, h.Text.Min)
+	case n > max:
+		return fmt.Errorf("text is %d characters, maximum is %d", n, max)
+	case h.pattern != nil && !h.pattern.MatchString(value):
+		return fmt.Errorf("text does not match pattern %q", h.Text.Pattern)
+	}
+	return nil
+}
+
+// Value returns the current value.
+func (h *TextHandler) Value() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.value
+}
+
+// SetValue sets the current value, for example when it is changed on the device, and publishes
+// it. The value is validated, but not passed to Set.
+func (h *TextHandler) SetValue(value string) error {
+	err := h.Validate(value)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.value = value
+	h.mu.Unlock()
+
+	return h.publish(value)
+}
+
+func (h *TextHandler) publish(value string) error {
+	if h.Text.StateTopic == "" || h.Publisher == nil {
+		return nil
+	}
+
+	err := h.Publisher.Publish(h.Text.StateTopic, byte(h.Text.Qos), h.Text.Retain, []byte(value))
+	if err != nil {
+		return fmt.Errorf("could not publish text state: %v", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes the handler to the Text's command topic.
+func (h *TextHandler) Subscribe(s Subscriber) error {
+	if h.Text.CommandTopic == "" {
+		return fmt.Errorf("text has no command topic")
+	}
+	return s.Subscribe(h.Text.CommandTopic, byte(h.Text.Qos), func(m Message) { h.HandleMessage(m) })
+}
+
+// HandleMessage validates the value in the message and passes it to Set. The value becomes the
+// current value and is published if Set succeeds. Otherwise the error is passed to OnInvalid,
+// the current value is published again, and the error is returned.
+func (h *TextHandler) HandleMessage(m Message) error {
+	value := string(m.Payload)
+
+	err := h.Validate(value)
+	if err == nil && h.Set != nil {
+		err = h.Set(value)
+	}
+	if err != nil {
+		if h.OnInvalid != nil {
+			h.OnInvalid(value, err)
+		}
+		if perr := h.publish(h.Value()); perr != nil {
+			return fmt.Errorf("%v, and %v", err, perr)
+		}
+		return err
+	}
+
+	h.mu.Lock()
+	h.value = value
+	h.mu.Unlock()
+
+	return h.publish(value)
+}
@@ -0,0 +1,63 @@
+package discovery
+
+import "testing"
+
+func TestTextHandler(t *testing.T) {
+	p := &testPublisher{}
+	txt := &Text{
+		CommandTopic: "wifi/ssid/set",
+		StateTopic:   "wifi/ssid",
+		Min:          2,
+		Max:          8,
+		Pattern:      "[a-z]+",
+	}
+
+	set := []string{}
+	invalid := []string{}
+	h, err := NewTextHandler(txt, p, func(v string) error {
+		set = append(set, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not create handler: %v", err)
+	}
+	h.OnInvalid = func(v string, err error) { invalid = append(invalid, v) }
+
+	if err := h.SetValue("home"); err != nil {
+		t.Fatalf("could not set value: %v", err)
+	}
+
+	for _, v := range []string{"a", "waytoolongssid", "Home", "ü"} {
+		if err := h.HandleMessage(Message{Topic: txt.CommandTopic, Payload: []byte(v)}); err == nil {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+	if err := h.HandleMessage(Message{Topic: txt.CommandTopic, Payload: []byte("office")}); err != nil {
+		t.Fatalf("could not handle message: %v", err)
+	}
+
+	if len(set) != 1 || set[0] != "office" {
+		t.Errorf("set %v, want [office]", set)
+	}
+	if len(invalid) != 4 {
+		t.Errorf("got %d invalid values, want 4: %v", len(invalid), invalid)
+	}
+	if h.Value() != "office" {
+		t.Errorf("value is %q, want office", h.Value())
+	}
+
+	// the current value is published again after each invalid value
+	want := []string{"home", "home", "home", "home", "home", "office"}
+	if len(*p) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(*p), len(want), *p)
+	}
+	for i, m := range *p {
+		if m.topic != txt.StateTopic || m.payload != want[i] {
+			t.Errorf("message %d is %+v, want %s", i, m, want[i])
+		}
+	}
+
+	if _, err := NewTextHandler(&Text{Pattern: "("}, p, nil); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
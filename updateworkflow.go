@@ -0,0 +1,193 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// UpdateState is the JSON state of an Update, published to its StateTopic.
+type UpdateState struct {
+	InstalledVersion string `json:"installed_version"`
+	LatestVersion    string `json:"latest_version,omitempty"`
+	Title            string `json:"title,omitempty"`
+	ReleaseSummary   string `json:"release_summary,omitempty"`
+	ReleaseURL       string `json:"release_url,omitempty"`
+	EntityPicture    string `json:"entity_picture,omitempty"`
+	InProgress       bool   `json:"in_progress"`
+	// UpdatePercentage is the progress of the install, or nil if it is not known.
+	UpdatePercentage *float64 `json:"update_percentage"`
+}
+
+// Installer installs version, calling progress with the percentage done as it goes.
+type Installer func(version string, progress func(percent float64)) error
+
+// UpdateWorkflow drives the firmware update of a device from an Update. It publishes the
+// installed and latest versions, and runs the Installer when the install button is pressed in
+// Home Assistant, publishing the progress of the install.
+type UpdateWorkflow struct {
+	Update    *Update
+	Publisher Publisher
+	Install   Installer
+	// OnError is called with errors from installs started by HandleMessage, and from
+	// publishing their progress.
+	OnError func(error)
+
+	mu    sync.Mutex
+	state UpdateState
+}
+
+// NewUpdateWorkflow creates an UpdateWorkflow for u that publishes with p and installs with
+// install.
+func NewUpdateWorkflow(u *Update, p Publisher, install Installer) *UpdateWorkflow {
+	return &UpdateWorkflow{
+		Update:    u,
+		Publisher: p,
+		Install:   install,
+		state: UpdateState{
+			Title:          u.Title,
+			ReleaseSummary: u.ReleaseSummary,
+			ReleaseURL:     u.ReleaseUrl,
+		},
+	}
+}
+
+// State returns the current state.
+func (w *UpdateWorkflow) State() UpdateState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// SetState replaces the state, except for the progress of a running install, and publishes it.
+func (w *UpdateWorkflow) SetState(s UpdateState) error {
+	w.mu.Lock()
+	s.InProgress = w.state.InProgress
+	s.UpdatePercentage = w.state.UpdatePercentage
+	w.state = s
+	w.mu.Unlock()
+
+	return w.publish(s)
+}
+
+// SetVersions sets the installed and latest versions and publishes the state.
+func (w *UpdateWorkflow) SetVersions(installed, latest string) error {
+	w.mu.Lock()
+	w.state.InstalledVersion = installed
+	w.state.LatestVersion = latest
+	s := w.state
+	w.mu.Unlock()
+
+	return w.publish(s)
+}
+
+func (w *UpdateWorkflow) publish(s UpdateState) error {
+	bs, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("could not marshal update state: %v", err)
+	}
+
+	err = w.Publisher.Publish(w.Update.StateTopic, byte(w.Update.Qos), w.Update.Retain, bs)
+	if err != nil {
+		return fmt.Errorf("could not publish update state: %v", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes the workflow to the Update's command topic.
+func (w *UpdateWorkflow) Subscribe(s Subscriber) error {
+	if w.Update.CommandTopic == "" {
+		return fmt.Errorf("update has no command topic")
+	}
+	return s.Subscribe(w.Update.CommandTopic, byte(w.Update.Qos), func(m Message) { w.HandleMessage(m) })
+}
+
+// HandleMessage starts an install in the background when the message is the Update's
+// PayloadInstall, or any message if PayloadInstall is not set. Retained messages are ignored,
+// so an install is not repeated when reconnecting.
+func (w *UpdateWorkflow) HandleMessage(m Message) {
+	if m.Retained {
+		return
+	}
+	if w.Update.PayloadInstall != "" && string(m.Payload) != w.Update.PayloadInstall {
+		return
+	}
+
+	go func() {
+		err := w.Run()
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}()
+}
+
+// Run installs the latest version, publishing the progress. On success the latest version
+// becomes the installed version. Run returns an error if an install is already in progress, or
+// if there is no newer version to install.
+func (w *UpdateWorkflow) Run() error {
+	w.mu.Lock()
+	if w.state.InProgress {
+		w.mu.Unlock()
+		return fmt.Errorf("install already in progress")
+	}
+	if w.state.LatestVersion == "" || w.state.LatestVersion == w.state.InstalledVersion {
+		w.mu.Unlock()
+		return fmt.Errorf("no update to install")
+	}
+	version := w.state.LatestVersion
+	w.state.InProgress = true
+	w.state.UpdatePercentage = nil
+	s := w.state
+	w.mu.Unlock()
+
+	err := w.publish(s)
+	if err != nil {
+		w.finish("")
+		return err
+	}
+
+	err = w.Install(version, w.progress)
+	if err != nil {
+		w.finish("")
+		return fmt.Errorf("could not install %s: %v", version, err)
+	}
+
+	return w.finish(version)
+}
+
+func (w *UpdateWorkflow) progress(percent float64) {
+	switch {
+	case percent < 0:
+		percent = 0
+	case percent > 100:
+		percent = 100
+	}
+
+	w.mu.Lock()
+	if !w.state.InProgress {
+		w.mu.Unlock()
+		return
+	}
+	w.state.UpdatePercentage = &percent
+	s := w.state
+	w.mu.Unlock()
+
+	err := w.publish(s)
+	if err != nil && w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// finish ends an install, setting the installed version if it is not empty.
+func (w *UpdateWorkflow) finish(installed string) error {
+	w.mu.Lock()
+	w.state.InProgress = false
+	w.state.UpdatePercentage = nil
+	if installed != "" {
+		w.state.InstalledVersion = installed
+	}
+	s := w.state
+	w.mu.Unlock()
+
+	return w.publish(s)
+}
@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestUpdateWorkflow(t *testing.T) {
+	p := &testPublisher{}
+	u := &Update{
+		StateTopic:     "device/update/state",
+		CommandTopic:   "device/update/install",
+		PayloadInstall: "install",
+		Retain:         true,
+	}
+
+	done := make(chan error, 1)
+	w := NewUpdateWorkflow(u, p, func(version string, progress func(float64)) error {
+		if version != "1.1.0" {
+			return errors.New("wrong version")
+		}
+		progress(50)
+		progress(150)
+		return nil
+	})
+	w.OnError = func(err error) { done <- err }
+
+	if err := w.Run(); err == nil {
+		t.Fatalf("expected an error installing without a latest version")
+	}
+
+	if err := w.SetVersions("1.0.0", "1.1.0"); err != nil {
+		t.Fatalf("could not set versions: %v", err)
+	}
+
+	w.HandleMessage(Message{Topic: u.CommandTopic, Payload: []byte("install"), Retained: true})
+	w.HandleMessage(Message{Topic: u.CommandTopic, Payload: []byte("reboot")})
+	if err := w.Run(); err != nil {
+		t.Fatalf("could not install: %v", err)
+	}
+
+	states := []UpdateState{}
+	for _, m := range *p {
+		if m.topic != u.StateTopic || !m.retained {
+			t.Fatalf("unexpected message: %+v", m)
+		}
+		s := UpdateState{}
+		if err := json.Unmarshal([]byte(m.payload), &s); err != nil {
+			t.Fatalf("state is not json: %v", err)
+		}
+		states = append(states, s)
+	}
+
+	if len(states) != 5 {
+		t.Fatalf("got %d states, want 5: %+v", len(states), states)
+	}
+	if !states[1].InProgress || states[1].UpdatePercentage != nil {
+		t.Errorf("install did not start: %+v", states[1])
+	}
+	if pct := states[3].UpdatePercentage; pct == nil || *pct != 100 {
+		t.Errorf("progress was not clamped: %+v", states[3])
+	}
+	last := states[4]
+	if last.InProgress || last.InstalledVersion != "1.1.0" || last.UpdatePercentage != nil {
+		t.Errorf("install did not finish: %+v", last)
+	}
+
+	// nothing left to install
+	w.HandleMessage(Message{Topic: u.CommandTopic, Payload: []byte("install")})
+	if err := <-done; err == nil {
+		t.Errorf("expected an error with nothing to install")
+	}
+}
@@ -119,7 +119,9 @@ type Vacuum struct {
 
 // AnnounceTopic returns the topic to announce the discoverable Vacuum
 // Topic has the format below:
-//   <discovery_prefix>/<component>/<object_id>/config
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
 // 'object_id' is either the UniqueId, the Name, or a hash of the Vacuum
 func (d *Vacuum) AnnounceTopic(prefix string) string {
 	topicFormat := "%s/vacuum/%s/config"
@@ -0,0 +1,136 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultVacuumFeatures are the features of a Vacuum when SupportedFeatures is not set.
+var DefaultVacuumFeatures = []string{
+	VacuumFeatureStart,
+	VacuumFeatureStop,
+	VacuumFeatureReturnHome,
+	VacuumFeatureStatus,
+	VacuumFeatureBattery,
+	VacuumFeatureCleanSpot,
+}
+
+// VacuumStatus is the JSON published to the StateTopic of a Vacuum. State is required.
+type VacuumStatus struct {
+	State VacuumState `json:"state"`
+	// BatteryLevel is a percentage, and needs the battery feature.
+	BatteryLevel *int `json:"battery_level,omitempty"`
+	// FanSpeed must be one of the FanSpeedList, and needs the fan_speed feature.
+	FanSpeed string `json:"fan_speed,omitempty"`
+}
+
+// VacuumCommand is a command sent to a Vacuum. Feature is the feature the command needs. It
+// is fan_speed for commands on the SetFanSpeedTopic, send_command for commands on the
+// SendCommandTopic, and the feature of the payload for commands on the CommandTopic.
+type VacuumCommand struct {
+	Feature string
+	// FanSpeed is set by commands on the SetFanSpeedTopic.
+	FanSpeed string
+	// Command and Params are set by commands on the SendCommandTopic.
+	Command string
+	Params  map[string]json.RawMessage
+}
+
+// Features returns the supported features.
+func (v *Vacuum) Features() []string {
+	if len(v.SupportedFeatures) == 0 {
+		return DefaultVacuumFeatures
+	}
+	return v.SupportedFeatures
+}
+
+// Supports returns whether the vacuum supports the feature.
+func (v *Vacuum) Supports(feature string) bool {
+	return contains(v.Features(), feature)
+}
+
+// EncodeStatus encodes the status to publish to the StateTopic. It returns an error if the
+// status uses a feature the vacuum doesn't support.
+func (v *Vacuum) EncodeStatus(s VacuumStatus) ([]byte, error) {
+	if s.State == "" {
+		return nil, fmt.Errorf("vacuum status has no state")
+	}
+	if s.BatteryLevel != nil {
+		if !v.Supports(VacuumFeatureBattery) {
+			return nil, fmt.Errorf("vacuum does not support the battery feature")
+		}
+		if *s.BatteryLevel < 0 || *s.BatteryLevel > 100 {
+			return nil, fmt.Errorf("battery level %d is outside 0 to 100", *s.BatteryLevel)
+		}
+	}
+	if s.FanSpeed != "" {
+		if !v.Supports(VacuumFeatureFanSpeed) {
+			return nil, fmt.Errorf("vacuum does not support the fan_speed feature")
+		}
+		if !contains(v.FanSpeedList, s.FanSpeed) {
+			return nil, fmt.Errorf("fan speed %q is not in the fan speed list", s.FanSpeed)
+		}
+	}
+	return json.Marshal(s)
+}
+
+// DecodeCommand decodes a command sent to topic, which must be one of the Vacuum's command
+// topics. It returns an error for commands that need a feature the vacuum doesn't support.
+func (v *Vacuum) DecodeCommand(topic string, payload []byte) (VacuumCommand, error) {
+	var c VacuumCommand
+	p := strings.TrimSpace(string(payload))
+
+	switch topic {
+	case "":
+		return c, fmt.Errorf("no topic")
+
+	case v.CommandTopic:
+		for _, cmd := range []struct{ payload, feature string }{
+			{orDefault(v.PayloadStart, "start"), VacuumFeatureStart},
+			{orDefault(v.PayloadStop, "stop"), VacuumFeatureStop},
+			{orDefault(v.PayloadPause, "pause"), VacuumFeaturePause},
+			{orDefault(v.PayloadReturnToBase, "return_to_base"), VacuumFeatureReturnHome},
+			{orDefault(v.PayloadLocate, "locate"), VacuumFeatureLocate},
+			{orDefault(v.PayloadCleanSpot, "clean_spot"), VacuumFeatureCleanSpot},
+		} {
+			if p == cmd.payload {
+				c.Feature = cmd.feature
+				break
+			}
+		}
+		if c.Feature == "" {
+			return c, fmt.Errorf("unknown vacuum command %q", p)
+		}
+
+	case v.SetFanSpeedTopic:
+		c.Feature = VacuumFeatureFanSpeed
+		if !contains(v.FanSpeedList, p) {
+			return c, fmt.Errorf("fan speed %q is not in the fan speed list", p)
+		}
+		c.FanSpeed = p
+
+	case v.SendCommandTopic:
+		c.Feature = VacuumFeatureSendCommand
+		// commands with parameters are sent as JSON, with the command in "command"
+		if !strings.HasPrefix(p, "{") {
+			c.Command = p
+			break
+		}
+		if err := json.Unmarshal([]byte(p), &c.Params); err != nil {
+			return c, fmt.Errorf("could not decode vacuum command: %v", err)
+		}
+		if err := json.Unmarshal(c.Params["command"], &c.Command); err != nil {
+			return c, fmt.Errorf("vacuum command has no command")
+		}
+		delete(c.Params, "command")
+
+	default:
+		return c, fmt.Errorf("%s is not a command topic of the vacuum", topic)
+	}
+
+	if !v.Supports(c.Feature) {
+		return c, fmt.Errorf("vacuum does not support the %s feature", c.Feature)
+	}
+	return c, nil
+}
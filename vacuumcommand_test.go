@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVacuumEncodeStatus(t *testing.T) {
+	v := &Vacuum{
+		SupportedFeatures: []string{VacuumFeatureStart, VacuumFeatureBattery, VacuumFeatureFanSpeed},
+		FanSpeedList:      []string{"low", "max"},
+	}
+	battery := 61
+	bs, err := v.EncodeStatus(VacuumStatus{State: VacuumStateCleaning, BatteryLevel: &battery, FanSpeed: "max"})
+	if err != nil {
+		t.Fatalf("could not encode status: %v", err)
+	}
+	if want := `{"state":"cleaning","battery_level":61,"fan_speed":"max"}`; string(bs) != want {
+		t.Errorf("got %s, want %s", bs, want)
+	}
+
+	battery = 101
+	for _, s := range []VacuumStatus{
+		{},
+		{State: VacuumStateDocked, BatteryLevel: &battery},
+		{State: VacuumStateDocked, FanSpeed: "turbo"},
+	} {
+		if _, err := v.EncodeStatus(s); err == nil {
+			t.Errorf("expected an error encoding %+v", s)
+		}
+	}
+
+	v.SupportedFeatures = nil
+	if _, err := v.EncodeStatus(VacuumStatus{State: VacuumStateIdle, FanSpeed: "low"}); err == nil {
+		t.Errorf("expected an error encoding a fan speed without the feature")
+	}
+}
+
+func TestVacuumDecodeCommand(t *testing.T) {
+	v := &Vacuum{
+		CommandTopic:      "vacuum/command",
+		SetFanSpeedTopic:  "vacuum/fan_speed",
+		SendCommandTopic:  "vacuum/send_command",
+		PayloadStart:      "GO",
+		FanSpeedList:      []string{"low", "max"},
+		SupportedFeatures: []string{VacuumFeatureStart, VacuumFeatureReturnHome, VacuumFeatureFanSpeed, VacuumFeatureSendCommand},
+	}
+
+	c, err := v.DecodeCommand("vacuum/command", []byte("GO"))
+	if err != nil || c.Feature != VacuumFeatureStart {
+		t.Errorf("could not decode start: %+v, %v", c, err)
+	}
+	c, err = v.DecodeCommand("vacuum/command", []byte("return_to_base"))
+	if err != nil || c.Feature != VacuumFeatureReturnHome {
+		t.Errorf("could not decode return to base: %+v, %v", c, err)
+	}
+	c, err = v.DecodeCommand("vacuum/fan_speed", []byte("max"))
+	if err != nil || c.FanSpeed != "max" {
+		t.Errorf("could not decode fan speed: %+v, %v", c, err)
+	}
+	c, err = v.DecodeCommand("vacuum/send_command", []byte("empty_bin"))
+	if err != nil || c.Command != "empty_bin" || c.Params != nil {
+		t.Errorf("could not decode command: %+v, %v", c, err)
+	}
+
+	c, err = v.DecodeCommand("vacuum/send_command", []byte(`{"command": "clean_room", "room": "kitchen", "passes": 2}`))
+	if err != nil || c.Command != "clean_room" {
+		t.Fatalf("could not decode command with params: %+v, %v", c, err)
+	}
+	var room string
+	if err := json.Unmarshal(c.Params["room"], &room); err != nil || room != "kitchen" || len(c.Params) != 2 {
+		t.Errorf("got params %v", c.Params)
+	}
+
+	for _, tc := range []struct{ topic, payload string }{
+		{"vacuum/command", "pause"},
+		{"vacuum/command", "start"},
+		{"vacuum/fan_speed", "turbo"},
+		{"vacuum/send_command", `{"room": "kitchen"}`},
+		{"vacuum/other", "GO"},
+	} {
+		if _, err := v.DecodeCommand(tc.topic, []byte(tc.payload)); err == nil {
+			t.Errorf("expected an error decoding %s on %s", tc.payload, tc.topic)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package discovery
+
+import "fmt"
+
+type Valve struct {
+
+	// A list of MQTT topics subscribed to receive availability (online/offline) updates. Must not be used together with `availability_topic`
+	// Default: <no value>
+	Availability []Availability `json:"availability,omitempty"`
+
+	// When `availability` is configured, this controls the conditions needed to set the entity to `available`. Valid entries are `all`, `any`, and `latest`. If set to `all`, `payload_available` must be received on all configured availability topics before the entity is marked as online. If set to `any`, `payload_available` must be received on at least one configured availability topic before the entity is marked as online. If set to `latest`, the last `payload_available` or `payload_not_available` received on any configured availability topic controls the availability
+	// Default: latest
+	AvailabilityMode string `json:"availability_mode,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract device's availability from the `availability_topic`. To determine the devices's availability result of this template will be compared to `payload_available` and `payload_not_available`
+	// Default: <no value>
+	AvailabilityTemplate string `json:"availability_template,omitempty"`
+
+	// The subscribed-to MQTT topic to receive birth and LWT messages from the MQTT valve. If an `availability` topic is not defined, the valve availability state will always be `available`. If an `availability` topic is defined, the valve availability state will be `unavailable` by default. Must not be used together with `availability`
+	// Default: <no value>
+	AvailabilityTopic string `json:"availability_topic,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to generate the payload to send to `command_topic`
+	// Default: <no value>
+	CommandTemplate string `json:"command_template,omitempty"`
+
+	// The MQTT topic to publish commands to control the valve. The value sent can be a value defined by `payload_open`, `payload_close` or `payload_stop`. If `reports_position` is set to `true`, a numeric value will be published instead
+	// Default: <no value>
+	CommandTopic string `json:"command_topic,omitempty"`
+
+	// Information about the device this valve is a part of to tie it into the [device registry](https://developers.home-assistant.io/docs/en/device_registry_index.html). Only works when [`unique_id`](#unique_id) is set. At least one of identifiers or connections must be present to identify the device
+	// Default: <no value>
+	Device *Device `json:"device,omitempty"`
+
+	// Sets the [class of the device](/integrations/valve/#device_class), changing the device state and icon that is displayed on the frontend. The `device_class` can be `null`
+	// Default: <no value>
+	DeviceClass string `json:"device_class,omitempty"`
+
+	// Flag which defines if the entity should be enabled when first added
+	// Default: true
+	EnabledByDefault bool `json:"enabled_by_default,omitempty"`
+
+	// The encoding of the payloads received and published messages. Set to `""` to disable decoding of incoming payload
+	// Default: utf-8
+	Encoding string `json:"encoding,omitempty"`
+
+	// The [category](https://developers.home-assistant.io/docs/core/entity#generic-properties) of the entity
+	// Default: <no value>
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	// Picture URL for the entity
+	// Default: <no value>
+	EntityPicture string `json:"entity_picture,omitempty"`
+
+	// [Icon](/docs/configuration/customizing-devices/#icon) for the entity
+	// Default: <no value>
+	Icon string `json:"icon,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) to extract the JSON dictionary from messages received on the `json_attributes_topic`. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-template-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTemplate string `json:"json_attributes_template,omitempty"`
+
+	// The MQTT topic subscribed to receive a JSON dictionary payload and then set as sensor attributes. Usage example can be found in [MQTT sensor](/integrations/sensor.mqtt/#json-attributes-topic-configuration) documentation
+	// Default: <no value>
+	JsonAttributesTopic string `json:"json_attributes_topic,omitempty"`
+
+	// The name of the valve. Can be set to `null` if only the device name is relevant
+	// Default: MQTT valve
+	Name string `json:"name,omitempty"`
+
+	// Used instead of `name` for automatic generation of `entity_id
+	// Default: <no value>
+	ObjectId string `json:"object_id,omitempty"`
+
+	// Flag that defines if a valve works in optimistic mode
+	// Default: `false` if `state_topic` defined, else `true`.
+	Optimistic bool `json:"optimistic,omitempty"`
+
+	// The payload that represents the online state
+	// Default: online
+	PayloadAvailable string `json:"payload_available,omitempty"`
+
+	// The command payload that closes the valve. Is only used when `reports_position` is set to `false` (default). The `payload_close` is not allowed if `reports_position` is set to `true`. Can be set to `null` to disable the valve's close option
+	// Default: CLOSE
+	PayloadClose string `json:"payload_close,omitempty"`
+
+	// The payload that represents the offline state
+	// Default: offline
+	PayloadNotAvailable string `json:"payload_not_available,omitempty"`
+
+	// The command payload that opens the valve. Is only used when `reports_position` is set to `false` (default). The `payload_open` is not allowed if `reports_position` is set to `true`. Can be set to `null` to disable the valve's open option
+	// Default: OPEN
+	PayloadOpen string `json:"payload_open,omitempty"`
+
+	// The command payload that stops the valve. When not configured, the valve will not support the `valve.stop` action
+	// Default: <no value>
+	PayloadStop string `json:"payload_stop,omitempty"`
+
+	// Must be `valve`. Only allowed and required in [MQTT auto discovery device messages](/integrations/mqtt/#device-discovery-payload)
+	// Default: <no value>
+	Platform string `json:"platform"`
+
+	// Number which represents closed position
+	// Default: 0
+	PositionClosed *int `json:"position_closed,omitempty"`
+
+	// Number which represents open position
+	// Default: 100
+	PositionOpen *int `json:"position_open,omitempty"`
+
+	// The maximum QoS level to be used when receiving and publishing messages
+	// Default: 0
+	Qos int `json:"qos,omitempty"`
+
+	// Set to `true` if the valve reports the position or supports setting the position. Enabling the `reports_position` option will cause the position to be published instead of a payload defined by `payload_open`, `payload_close` or `payload_stop`. When receiving messages, `state_topic` will accept numeric payloads or one of the following state messages: `open`, `opening`, `closed`, or `closing`
+	// Default: false
+	ReportsPosition bool `json:"reports_position,omitempty"`
+
+	// Defines if published messages should have the retain flag set
+	// Default: false
+	Retain bool `json:"retain,omitempty"`
+
+	// The payload that represents the closed state. Is only allowed when `reports_position` is set to `False` (default)
+	// Default: closed
+	StateClosed string `json:"state_closed,omitempty"`
+
+	// The payload that represents the closing state
+	// Default: closing
+	StateClosing string `json:"state_closing,omitempty"`
+
+	// The payload that represents the open state. Is only allowed when `reports_position` is set to `False` (default)
+	// Default: open
+	StateOpen string `json:"state_open,omitempty"`
+
+	// The payload that represents the opening state
+	// Default: opening
+	StateOpening string `json:"state_opening,omitempty"`
+
+	// The MQTT topic subscribed to receive valve state messages. State topic accepts a state payload (`open`, `opening`, `closed`, or `closing`) or, if `reports_position` is supported, a numeric value representing the position. In a JSON format with variables `state` and `position` both values can be received together. A "None" state value resets to an `unknown` state. An empty string is ignored
+	// Default: <no value>
+	StateTopic string `json:"state_topic,omitempty"`
+
+	// An ID that uniquely identifies this valve. If two valves have the same unique ID, Home Assistant will raise an exception. Required when used with device-based discovery
+	// Default: <no value>
+	UniqueId string `json:"unique_id,omitempty"`
+
+	// Defines a [template](/docs/configuration/templating/#using-templates-with-the-mqtt-integration) that can be used to extract the payload for the `state_topic` topic. The rendered value should be a defined state payload or, if reporting a position is supported and `reports_position` is set to `true`, a numeric value is expected representing the position. See also `state_topic`
+	// Default: <no value>
+	ValueTemplate string `json:"value_template,omitempty"`
+}
+
+// AnnounceTopic returns the topic to announce the discoverable Valve
+// Topic has the format below:
+//
+//	<discovery_prefix>/<component>/<object_id>/config
+//
+// 'object_id' is either the UniqueId, the Name, or a hash of the Valve
+func (d *Valve) AnnounceTopic(prefix string) string {
+	topicFormat := "%s/valve/%s/config"
+	objectID := ""
+	switch {
+	case d.UniqueId != "":
+		objectID = d.UniqueId
+	case d.Name != "":
+		objectID = d.Name
+	default:
+		objectID = hash(d)
+	}
+
+	return fmt.Sprintf(topicFormat, prefix, objectID)
+}
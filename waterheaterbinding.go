@@ -0,0 +1,214 @@
+package discovery
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultWaterHeaterModes are the modes of a WaterHeater when Modes is not set.
+var DefaultWaterHeaterModes = []WaterHeaterMode{
+	WaterHeaterModeOff,
+	WaterHeaterModeEco,
+	WaterHeaterModeElectric,
+	WaterHeaterModeGas,
+	WaterHeaterModeHeatPump,
+	WaterHeaterModeHighDemand,
+	WaterHeaterModePerformance,
+}
+
+// WaterHeaterState is the state of a water heater.
+type WaterHeaterState struct {
+	Mode               WaterHeaterMode
+	Temperature        float64
+	CurrentTemperature float64
+	Power              bool
+}
+
+// WaterHeaterBinding binds a water heater to the command and state topics of a WaterHeater.
+// Commands from Home Assistant are checked and passed to the On functions. When they succeed
+// the state is updated and published. State changes on the device are published with the Set
+// methods.
+type WaterHeaterBinding struct {
+	WaterHeater *WaterHeater
+	Publisher   Publisher
+	Settings    TemperatureSettings
+
+	OnMode        func(WaterHeaterMode) error
+	OnTemperature func(float64) error
+	OnPower       func(bool) error
+	// OnError is called with the errors from handling commands.
+	OnError func(error)
+
+	mu    sync.Mutex
+	state WaterHeaterState
+}
+
+// NewWaterHeaterBinding creates a WaterHeaterBinding for w that publishes with p. The target
+// temperature starts at the initial temperature, and the mode at off.
+func NewWaterHeaterBinding(w *WaterHeater, p Publisher) (*WaterHeaterBinding, error) {
+	ts, err := w.TemperatureSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WaterHeaterBinding{
+		WaterHeater: w,
+		Publisher:   p,
+		Settings:    ts,
+		state: WaterHeaterState{
+			Mode:        WaterHeaterModeOff,
+			Temperature: ts.Initial,
+		},
+	}, nil
+}
+
+// State returns the current state.
+func (b *WaterHeaterBinding) State() WaterHeaterState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Modes returns the supported modes.
+func (b *WaterHeaterBinding) Modes() []WaterHeaterMode {
+	if len(b.WaterHeater.Modes) == 0 {
+		return DefaultWaterHeaterModes
+	}
+	return b.WaterHeater.Modes
+}
+
+func (b *WaterHeaterBinding) supports(mode WaterHeaterMode) bool {
+	for _, m := range b.Modes() {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *WaterHeaterBinding) publish(topic, payload string) error {
+	if topic == "" {
+		return nil
+	}
+	err := b.Publisher.Publish(topic, byte(b.WaterHeater.Qos), b.WaterHeater.Retain, []byte(payload))
+	if err != nil {
+		return fmt.Errorf("could not publish to %s: %v", topic, err)
+	}
+	return nil
+}
+
+// SetMode sets the mode and publishes it to the ModeStateTopic.
+func (b *WaterHeaterBinding) SetMode(mode WaterHeaterMode) error {
+	if !b.supports(mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+
+	b.mu.Lock()
+	b.state.Mode = mode
+	b.mu.Unlock()
+
+	return b.publish(b.WaterHeater.ModeStateTopic, string(mode))
+}
+
+// SetTemperature sets the target temperature and publishes it to the TemperatureStateTopic.
+func (b *WaterHeaterBinding) SetTemperature(t float64) error {
+	t = b.Settings.Round(b.Settings.Clamp(t))
+
+	b.mu.Lock()
+	b.state.Temperature = t
+	b.mu.Unlock()
+
+	return b.publish(b.WaterHeater.TemperatureStateTopic, b.Settings.Format(t))
+}
+
+// SetCurrentTemperature sets the measured temperature and publishes it to the
+// CurrentTemperatureTopic.
+func (b *WaterHeaterBinding) SetCurrentTemperature(t float64) error {
+	b.mu.Lock()
+	b.state.CurrentTemperature = t
+	b.mu.Unlock()
+
+	return b.publish(b.WaterHeater.CurrentTemperatureTopic, b.Settings.Format(t))
+}
+
+// SetPower sets whether the water heater is on. A WaterHeater has no power state topic, so the
+// power is not published.
+func (b *WaterHeaterBinding) SetPower(on bool) {
+	b.mu.Lock()
+	b.state.Power = on
+	b.mu.Unlock()
+}
+
+// Subscribe subscribes the binding to the WaterHeater's command topics.
+func (b *WaterHeaterBinding) Subscribe(s Subscriber) error {
+	for _, sub := range []struct {
+		topic   string
+		handler func(Message) error
+	}{
+		{b.WaterHeater.ModeCommandTopic, b.HandleMode},
+		{b.WaterHeater.TemperatureCommandTopic, b.HandleTemperature},
+		{b.WaterHeater.PowerCommandTopic, b.HandlePower},
+	} {
+		if sub.topic == "" {
+			continue
+		}
+		handler := sub.handler
+		err := s.Subscribe(sub.topic, byte(b.WaterHeater.Qos), func(m Message) {
+			if err := handler(m); err != nil && b.OnError != nil {
+				b.OnError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("could not subscribe to %s: %v", sub.topic, err)
+		}
+	}
+	return nil
+}
+
+// HandleMode handles a message on the ModeCommandTopic.
+func (b *WaterHeaterBinding) HandleMode(m Message) error {
+	mode := WaterHeaterMode(m.Payload)
+	if !b.supports(mode) {
+		return fmt.Errorf("mode %q is not supported", mode)
+	}
+	if b.OnMode != nil {
+		if err := b.OnMode(mode); err != nil {
+			return err
+		}
+	}
+	return b.SetMode(mode)
+}
+
+// HandleTemperature handles a message on the TemperatureCommandTopic.
+func (b *WaterHeaterBinding) HandleTemperature(m Message) error {
+	t, err := b.Settings.Parse(m.Payload)
+	if err != nil {
+		return err
+	}
+	if b.OnTemperature != nil {
+		if err := b.OnTemperature(t); err != nil {
+			return err
+		}
+	}
+	return b.SetTemperature(t)
+}
+
+// HandlePower handles a message on the PowerCommandTopic.
+func (b *WaterHeaterBinding) HandlePower(m Message) error {
+	var on bool
+	switch string(m.Payload) {
+	case orDefault(b.WaterHeater.PayloadOn, "ON"):
+		on = true
+	case orDefault(b.WaterHeater.PayloadOff, "OFF"):
+	default:
+		return fmt.Errorf("unknown power payload %q", m.Payload)
+	}
+
+	if b.OnPower != nil {
+		if err := b.OnPower(on); err != nil {
+			return err
+		}
+	}
+	b.SetPower(on)
+	return nil
+}
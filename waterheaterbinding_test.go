@@ -0,0 +1,82 @@
+package discovery
+
+import "testing"
+
+func TestTemperatureSettings(t *testing.T) {
+	ts, err := (&Climate{}).TemperatureSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Unit != TemperatureUnitC || ts.Min != 7 || ts.Max != 35 || ts.Precision != 0.1 {
+		t.Errorf("wrong climate defaults: %+v", ts)
+	}
+	if got := ts.Format(21.44); got != "21.4" {
+		t.Errorf("formatted 21.44 as %s", got)
+	}
+
+	ts, err = (&WaterHeater{TemperatureUnit: "F", Precision: "0.5"}).TemperatureSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Min != 110 || ts.Max != 140 || ts.Precision != 0.5 {
+		t.Errorf("wrong water heater defaults: %+v", ts)
+	}
+	if got := ts.Format(120.3); got != "120.5" {
+		t.Errorf("formatted 120.3 as %s", got)
+	}
+
+	if _, err := (&WaterHeater{TemperatureUnit: "K"}).TemperatureSettings(); err == nil {
+		t.Errorf("expected an error for an unknown unit")
+	}
+}
+
+func TestWaterHeaterBinding(t *testing.T) {
+	p := &testPublisher{}
+	w := &WaterHeater{
+		ModeCommandTopic:        "boiler/mode/set",
+		ModeStateTopic:          "boiler/mode",
+		TemperatureCommandTopic: "boiler/temperature/set",
+		TemperatureStateTopic:   "boiler/temperature",
+		PowerCommandTopic:       "boiler/power/set",
+		Modes:                   []WaterHeaterMode{WaterHeaterModeOff, WaterHeaterModeEco, WaterHeaterModeHeatPump},
+	}
+	b, err := NewWaterHeaterBinding(w, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modes := []WaterHeaterMode{}
+	b.OnMode = func(m WaterHeaterMode) error {
+		modes = append(modes, m)
+		return nil
+	}
+	errs := 0
+	b.OnError = func(error) { errs++ }
+
+	s := testSubscriber{}
+	if err := b.Subscribe(s); err != nil {
+		t.Fatal(err)
+	}
+
+	s[w.ModeCommandTopic](Message{Payload: []byte("heat_pump")})
+	s[w.ModeCommandTopic](Message{Payload: []byte("gas")})
+	s[w.TemperatureCommandTopic](Message{Payload: []byte("55.04")})
+	s[w.TemperatureCommandTopic](Message{Payload: []byte("75")})
+	s[w.PowerCommandTopic](Message{Payload: []byte("ON")})
+
+	if len(modes) != 1 || modes[0] != WaterHeaterModeHeatPump {
+		t.Errorf("got modes %v", modes)
+	}
+	if errs != 2 {
+		t.Errorf("got %d errors, want 2", errs)
+	}
+
+	want := WaterHeaterState{Mode: WaterHeaterModeHeatPump, Temperature: 55, Power: true}
+	if got := b.State(); got != want {
+		t.Errorf("state is %+v, want %+v", got, want)
+	}
+
+	if len(*p) != 2 || (*p)[0].payload != "heat_pump" || (*p)[1].payload != "55.0" {
+		t.Errorf("published %+v", *p)
+	}
+}